@@ -48,6 +48,85 @@ var HumidityOutOfBound = AlarmString{Emergency | InstantNotification, "Humidity
 var TemperatureOutOfBound = AlarmString{Emergency | InstantNotification, "Temperature is outside of boundaries", 1 * time.Minute}
 var SensorReadoutIssue = AlarmString{Emergency, "Cannot read sensors", 2 * time.Second}
 var ClimateStateUndefined = AlarmString{Emergency, "Climate State Undefined", 2 * time.Second}
+var ClimateUpdated = AlarmString{Warning, "Climate season was updated", 2 * time.Second}
+
+// DelayedAlarm is an Alarm that only fires once its cause has
+// persisted for Delay.
+type DelayedAlarm interface {
+	Alarm
+	// Since returns when the cause of the alarm started.
+	Since() time.Time
+	Delay() time.Duration
+}
+
+// DescribedAlarm is an Alarm that details the measurement which
+// raised it, while its Reason stays the same for every occurrence.
+type DescribedAlarm interface {
+	Alarm
+	Description() string
+}
+
+// OutOfBoundAlarm is a TemperatureOutOfBound or HumidityOutOfBound
+// alarm, raised once the value was out of bound for a delay.
+type OutOfBoundAlarm struct {
+	AlarmString
+	since time.Time
+	delay time.Duration
+}
+
+func (a OutOfBoundAlarm) Since() time.Time {
+	return a.since
+}
+
+func (a OutOfBoundAlarm) Delay() time.Duration {
+	return a.delay
+}
+
+func NewOutOfBoundAlarm(a AlarmString, since time.Time, delay time.Duration) OutOfBoundAlarm {
+	return OutOfBoundAlarm{a, since, delay}
+}
+
+// RateAlarm is raised when the temperature or humidity changes
+// faster than allowed.
+type RateAlarm struct {
+	quantity string
+	unit     string
+	rate     float64
+}
+
+func (a RateAlarm) Flags() AlarmFlags {
+	return Emergency | InstantNotification
+}
+
+func (a RateAlarm) Reason() string {
+	return fmt.Sprintf("%s is changing too fast", a.quantity)
+}
+
+func (a RateAlarm) DeadLine() time.Duration {
+	return 1 * time.Minute
+}
+
+// Rate returns the measured rate of change, per minute.
+func (a RateAlarm) Rate() float64 {
+	return a.rate
+}
+
+// Description returns the measured rate of change.
+func (a RateAlarm) Description() string {
+	return fmt.Sprintf("%+.2f %s/min", a.rate, a.unit)
+}
+
+func (a RateAlarm) String() string {
+	return fmt.Sprintf("%s (%s)", a.Reason(), a.Description())
+}
+
+func NewTemperatureRateAlarm(rate float64) RateAlarm {
+	return RateAlarm{"Temperature", "°C", rate}
+}
+
+func NewHumidityRateAlarm(rate float64) RateAlarm {
+	return RateAlarm{"Humidity", "% R.H.", rate}
+}
 
 type MissingDeviceAlarm struct {
 	canInterface string
@@ -151,6 +230,33 @@ func (e DeviceInternalError) ErrorCode() uint16 {
 	return e.errorCode
 }
 
+// ClimateOverridden is raised while the climate of a zone is manually
+// overridden, until the override ends.
+type ClimateOverridden struct {
+	remaining time.Duration
+}
+
+func (a ClimateOverridden) Flags() AlarmFlags {
+	return Warning
+}
+
+func (a ClimateOverridden) Reason() string {
+	return "Climate is manually overridden"
+}
+
+func (a ClimateOverridden) DeadLine() time.Duration {
+	return a.remaining
+}
+
+// NewClimateOverriddenAlarm returns the alarm for an override lasting
+// remaining. A zero remaining duration marks the end of the override.
+func NewClimateOverriddenAlarm(remaining time.Duration) ClimateOverridden {
+	if remaining < 0 {
+		remaining = 0
+	}
+	return ClimateOverridden{remaining}
+}
+
 type AlarmStatus int
 
 const (
@@ -164,6 +270,9 @@ type AlarmEvent struct {
 	Flags          AlarmFlags
 	Status         AlarmStatus
 	Time           time.Time
+	// Description details the alarm, like the measured rate of
+	// change, if its Alarm is a DescribedAlarm.
+	Description string
 }
 
 func MapPriority(f AlarmFlags) int {
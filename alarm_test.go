@@ -20,6 +20,7 @@ func (s *AlarmSuite) TestRepeatPeriod(c *C) {
 		{NewFanAlarm("foo", arke.FanAging), 10 * time.Minute},
 		{NewMissingDeviceAlarm("foo", arke.ZeusClass, 1), 5 * HeartBeatPeriod},
 		{NewDeviceInternalError("foo", arke.ZeusClass, 1, 43), 2 * time.Second},
+		{NewOutOfBoundAlarm(TemperatureOutOfBound, time.Now(), 5*time.Minute), 1 * time.Minute},
 	}
 
 	for _, d := range testdata {
@@ -47,6 +48,9 @@ func (s *AlarmSuite) TestData(c *C) {
 		{NewFanAlarm("baz", arke.FanOK), "Fan baz is aging", Warning},
 		{NewMissingDeviceAlarm("vcan0", arke.ZeusClass, 1), "Device vcan0.Zeus.1 is missing", Emergency | InstantNotification},
 		{NewDeviceInternalError("vcan0", arke.ZeusClass, 1, 0x42), "Device vcan0.Zeus.1 internal error 0x0042", Warning},
+		{NewOutOfBoundAlarm(HumidityOutOfBound, time.Now(), time.Minute), "Humidity is outside of boundaries", Emergency | InstantNotification},
+		{NewTemperatureRateAlarm(0.8), "Temperature is changing too fast", Emergency | InstantNotification},
+		{NewHumidityRateAlarm(-4), "Humidity is changing too fast", Emergency | InstantNotification},
 	}
 
 	for _, d := range testdata {
@@ -56,6 +60,14 @@ func (s *AlarmSuite) TestData(c *C) {
 
 }
 
+func (s *AlarmSuite) TestRateAlarm(c *C) {
+	a := NewTemperatureRateAlarm(0.825)
+	c.Check(a.Rate(), Equals, 0.825)
+	c.Check(a.Description(), Equals, "+0.82 °C/min")
+	c.Check(a.String(), Equals, "Temperature is changing too fast (+0.82 °C/min)")
+	c.Check(NewHumidityRateAlarm(-4).String(), Equals, "Humidity is changing too fast (-4.00 % R.H./min)")
+}
+
 func (s *AlarmSuite) TestFanAlarm(c *C) {
 	testdata := []struct {
 		Alarm          FanAlarm
@@ -0,0 +1,225 @@
+package zeus
+
+import (
+	"fmt"
+	"math"
+)
+
+// ChannelInterpolation defines how the value of a Channel evolves
+// during a transition.
+type ChannelInterpolation int
+
+const (
+	// LinearChannel values follow the transition curve.
+	LinearChannel ChannelInterpolation = iota
+	// StepChannel values switch to their target at the end of the
+	// transition.
+	StepChannel
+)
+
+// ChannelValues holds the values of the channels registered in
+// addition to the built-in ones.
+type ChannelValues map[string]float64
+
+// Channel is a named climate value of a State, like its temperature
+// or the intensity of a light.
+type Channel struct {
+	// Name is the key of the channel in season files.
+	Name string
+	// Unit returns the BoundedUnit of a value of the channel.
+	Unit          func(v float64) BoundedUnit
+	Interpolation ChannelInterpolation
+
+	get      func(s State) float64
+	set      func(s *State, v float64)
+	table    func(d *StateDrift) *DriftTable
+	setTable func(d *StateDrift, t *DriftTable)
+}
+
+// Value returns the value of the channel in s. It is undefined if s
+// does not define the channel.
+func (c *Channel) Value(s State) BoundedUnit {
+	return c.Unit(c.get(s))
+}
+
+// Set sets the value of the channel in s.
+func (c *Channel) Set(s *State, v float64) {
+	c.set(s, v)
+}
+
+// Defined returns true if s defines a value, or a drift table, for
+// the channel.
+func (c *Channel) Defined(s State) bool {
+	return IsUndefined(c.Value(s)) == false || c.Drift(s.Drift) != nil
+}
+
+// Drift returns the drift table of the channel in d, if any.
+func (c *Channel) Drift(d *StateDrift) *DriftTable {
+	if d == nil {
+		return nil
+	}
+	return c.table(d)
+}
+
+var (
+	TemperatureChannel = &Channel{
+		Name:     "temperature",
+		Unit:     func(v float64) BoundedUnit { return Temperature(v) },
+		get:      func(s State) float64 { return s.Temperature.Value() },
+		set:      func(s *State, v float64) { s.Temperature = Temperature(v) },
+		table:    func(d *StateDrift) *DriftTable { return d.Temperature },
+		setTable: func(d *StateDrift, t *DriftTable) { d.Temperature = t },
+	}
+	HumidityChannel = &Channel{
+		Name:     "humidity",
+		Unit:     func(v float64) BoundedUnit { return Humidity(v) },
+		get:      func(s State) float64 { return s.Humidity.Value() },
+		set:      func(s *State, v float64) { s.Humidity = Humidity(v) },
+		table:    func(d *StateDrift) *DriftTable { return d.Humidity },
+		setTable: func(d *StateDrift, t *DriftTable) { d.Humidity = t },
+	}
+	WindChannel = &Channel{
+		Name:     "wind",
+		Unit:     func(v float64) BoundedUnit { return Wind(v) },
+		get:      func(s State) float64 { return s.Wind.Value() },
+		set:      func(s *State, v float64) { s.Wind = Wind(v) },
+		table:    func(d *StateDrift) *DriftTable { return d.Wind },
+		setTable: func(d *StateDrift, t *DriftTable) { d.Wind = t },
+	}
+	VisibleLightChannel = &Channel{
+		Name:     "visible-light",
+		Unit:     func(v float64) BoundedUnit { return Light(v) },
+		get:      func(s State) float64 { return s.VisibleLight.Value() },
+		set:      func(s *State, v float64) { s.VisibleLight = Light(v) },
+		table:    func(d *StateDrift) *DriftTable { return d.VisibleLight },
+		setTable: func(d *StateDrift, t *DriftTable) { d.VisibleLight = t },
+	}
+	UVLightChannel = &Channel{
+		Name:     "uv-light",
+		Unit:     func(v float64) BoundedUnit { return Light(v) },
+		get:      func(s State) float64 { return s.UVLight.Value() },
+		set:      func(s *State, v float64) { s.UVLight = Light(v) },
+		table:    func(d *StateDrift) *DriftTable { return d.UVLight },
+		setTable: func(d *StateDrift, t *DriftTable) { d.UVLight = t },
+	}
+)
+
+// channels is the registry of all channels. The built-in ones are
+// stored in the fields of State, to stay compatible with older
+// season files and RPC peers.
+var channels = append([]*Channel(nil), builtinChannels...)
+
+var builtinChannels = []*Channel{
+	TemperatureChannel,
+	HumidityChannel,
+	WindChannel,
+	VisibleLightChannel,
+	UVLightChannel,
+}
+
+// Channels returns all registered channels, built-in ones first.
+func Channels() []*Channel {
+	return append([]*Channel(nil), channels...)
+}
+
+// LookupChannel returns the channel registered as name.
+func LookupChannel(name string) (*Channel, bool) {
+	for _, c := range channels {
+		if c.Name == name {
+			return c, true
+		}
+	}
+	return nil, false
+}
+
+// RegisterChannel registers a new channel, whose values are stored in
+// State.Channels. It should be called at initialization, before any
+// State is read.
+func RegisterChannel(name string, unit func(v float64) BoundedUnit, interpolation ChannelInterpolation) (*Channel, error) {
+	if _, ok := LookupChannel(name); ok == true {
+		return nil, fmt.Errorf("channel '%s' is already registered", name)
+	}
+	c := &Channel{
+		Name:          name,
+		Unit:          unit,
+		Interpolation: interpolation,
+		get: func(s State) float64 {
+			if s.Channels == nil {
+				return math.Inf(-1)
+			}
+			if v, ok := (*s.Channels)[name]; ok == true {
+				return v
+			}
+			return math.Inf(-1)
+		},
+		set: func(s *State, v float64) {
+			// copies the values, as they may be shared with
+			// another State.
+			values := ChannelValues{}
+			if s.Channels != nil {
+				for k, old := range *s.Channels {
+					values[k] = old
+				}
+			}
+			if math.IsInf(v, -1) == true {
+				delete(values, name)
+			} else {
+				values[name] = v
+			}
+			s.Channels = nil
+			if len(values) > 0 {
+				s.Channels = &values
+			}
+		},
+		table: func(d *StateDrift) *DriftTable {
+			return d.Channels[name]
+		},
+		setTable: func(d *StateDrift, t *DriftTable) {
+			tables := map[string]*DriftTable{}
+			for k, old := range d.Channels {
+				tables[k] = old
+			}
+			if t == nil {
+				delete(tables, name)
+			} else {
+				tables[name] = t
+			}
+			if len(tables) == 0 {
+				tables = nil
+			}
+			d.Channels = tables
+		},
+	}
+	channels = append(channels, c)
+	return c, nil
+}
+
+// UnregisterChannel removes a channel registered with
+// RegisterChannel. Built-in channels cannot be removed.
+func UnregisterChannel(c *Channel) error {
+	for _, b := range builtinChannels {
+		if b == c {
+			return fmt.Errorf("cannot unregister built-in channel '%s'", c.Name)
+		}
+	}
+	for i, r := range channels {
+		if r == c {
+			channels = append(channels[:i:i], channels[i+1:]...)
+			return nil
+		}
+	}
+	return fmt.Errorf("channel '%s' is not registered", c.Name)
+}
+
+// interpolate returns the value of c during a transition from
+// from to to, at completion.
+func (c *Channel) interpolate(from, to State, completion float64) float64 {
+	f, t := c.get(from), c.get(to)
+	if c.Interpolation == StepChannel && math.IsInf(f, -1) == false && math.IsInf(t, -1) == false {
+		if completion < 1.0 {
+			return f
+		}
+		return t
+	}
+	return interpolate(f, t, completion)
+}
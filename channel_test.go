@@ -0,0 +1,148 @@
+package zeus
+
+import (
+	"bytes"
+	"encoding/gob"
+	"time"
+
+	yaml "gopkg.in/yaml.v2"
+
+	. "gopkg.in/check.v1"
+)
+
+// ChannelSuite registers its channels only while it runs, other
+// suites see the built-in ones.
+type ChannelSuite struct {
+	blueLightChannel, mistChannel *Channel
+}
+
+var _ = Suite(&ChannelSuite{})
+
+func (s *ChannelSuite) SetUpSuite(c *C) {
+	var err error
+	s.blueLightChannel, err = RegisterChannel("blue-light", func(v float64) BoundedUnit { return Light(v) }, LinearChannel)
+	c.Assert(err, IsNil)
+	s.mistChannel, err = RegisterChannel("mist", func(v float64) BoundedUnit { return Wind(v) }, StepChannel)
+	c.Assert(err, IsNil)
+}
+
+func (s *ChannelSuite) TearDownSuite(c *C) {
+	c.Check(UnregisterChannel(s.blueLightChannel), IsNil)
+	c.Check(UnregisterChannel(s.mistChannel), IsNil)
+	c.Check(Channels(), DeepEquals, builtinChannels)
+}
+
+func (s *ChannelSuite) TestRegistry(c *C) {
+	names := []string{}
+	for _, channel := range Channels() {
+		names = append(names, channel.Name)
+	}
+	c.Check(names[:5], DeepEquals, []string{"temperature", "humidity", "wind", "visible-light", "uv-light"})
+	channel, ok := LookupChannel("blue-light")
+	c.Check(ok, Equals, true)
+	c.Check(channel, Equals, s.blueLightChannel)
+	_, err := RegisterChannel("humidity", func(v float64) BoundedUnit { return Humidity(v) }, LinearChannel)
+	c.Check(err, ErrorMatches, "channel 'humidity' is already registered")
+	c.Check(UnregisterChannel(HumidityChannel), ErrorMatches, "cannot unregister built-in channel 'humidity'")
+	red, err := RegisterChannel("red-light", func(v float64) BoundedUnit { return Light(v) }, LinearChannel)
+	c.Assert(err, IsNil)
+	c.Check(UnregisterChannel(red), IsNil)
+	_, ok = LookupChannel("red-light")
+	c.Check(ok, Equals, false)
+	c.Check(UnregisterChannel(red), ErrorMatches, "channel 'red-light' is not registered")
+
+	state := State{Temperature: 22, Humidity: UndefinedHumidity}
+	c.Check(TemperatureChannel.Value(state), Equals, Temperature(22))
+	c.Check(HumidityChannel.Defined(state), Equals, false)
+	c.Check(s.blueLightChannel.Defined(state), Equals, false)
+	other := state
+	s.blueLightChannel.Set(&state, 40)
+	c.Check(s.blueLightChannel.Value(state), Equals, Light(40))
+	// values are not shared between copies
+	s.blueLightChannel.Set(&other, 10)
+	c.Check(s.blueLightChannel.Value(state), Equals, Light(40))
+}
+
+func (s *ChannelSuite) TestYAML(c *C) {
+	state := State{}
+	c.Assert(yaml.Unmarshal([]byte(`name: dawn
+temperature: 24
+blue-light:
+  table:
+    - {day: 1, value: 10}
+    - {day: 11, value: 60}
+mist: 20
+`), &state), IsNil)
+	c.Check(state.Temperature, Equals, Temperature(24))
+	c.Check(IsUndefined(state.Humidity), Equals, true)
+	c.Check(s.blueLightChannel.Value(state), Equals, Light(10))
+	c.Check(s.mistChannel.Value(state), Equals, Wind(20))
+	c.Assert(state.Drift, NotNil)
+	c.Check(s.blueLightChannel.Drift(state.Drift), DeepEquals, &DriftTable{Points: []DriftPoint{{1, 10}, {11, 60}}})
+
+	out, err := yaml.Marshal(state)
+	c.Assert(err, IsNil)
+	c.Check(string(out), Equals, `name: dawn
+blue-light:
+  table:
+  - day: 1
+    value: 10
+  - day: 11
+    value: 60
+mist: 20
+temperature: 24
+`)
+
+	c.Check(yaml.Unmarshal([]byte("name: dawn\ntemprature: 24\n"), &state), ErrorMatches, "state 'dawn': unknown channel 'temprature'")
+}
+
+func (s *ChannelSuite) TestInterpolation(c *C) {
+	from := State{Name: "night", Temperature: 20, Humidity: UndefinedHumidity, Wind: UndefinedWind, VisibleLight: UndefinedLight, UVLight: UndefinedLight}
+	to := from
+	to.Name = "day"
+	to.Temperature = 26
+	s.blueLightChannel.Set(&from, 0)
+	s.blueLightChannel.Set(&to, 80)
+	s.mistChannel.Set(&from, 10)
+	s.mistChannel.Set(&to, 50)
+
+	start := time.Date(2021, 3, 1, 6, 0, 0, 0, time.UTC)
+	i := climateTransition{start: start, from: from, to: to, duration: 10 * time.Minute}
+	middle := i.State(start.Add(5 * time.Minute))
+	c.Check(middle.Temperature, Equals, Temperature(23))
+	c.Check(s.blueLightChannel.Value(middle), Equals, Light(40))
+	c.Check(s.mistChannel.Value(middle), Equals, Wind(10))
+	c.Check(s.mistChannel.Value(i.State(start.Add(10*time.Minute))), Equals, Wind(50))
+
+	sanitized := SanitizeState(State{Name: "day"})
+	c.Check(s.blueLightChannel.Value(sanitized), Equals, Light(-1000))
+}
+
+func (s *ChannelSuite) TestRPCCompatibility(c *C) {
+	// State as known by older peers
+	type legacyState struct {
+		Name         string
+		Temperature  Temperature
+		Humidity     Humidity
+		Wind         Wind
+		VisibleLight Light
+		UVLight      Light
+		Drift        *StateDrift
+	}
+	legacy := legacyState{Name: "day", Temperature: 26, Humidity: 60, Wind: 100, VisibleLight: 30, UVLight: 10}
+	buffer := bytes.NewBuffer(nil)
+	c.Assert(gob.NewEncoder(buffer).Encode(legacy), IsNil)
+	decoded := State{}
+	c.Assert(gob.NewDecoder(buffer).Decode(&decoded), IsNil)
+	c.Check(decoded, Equals, State{Name: "day", Temperature: 26, Humidity: 60, Wind: 100, VisibleLight: 30, UVLight: 10})
+
+	s.blueLightChannel.Set(&decoded, 50)
+	buffer.Reset()
+	c.Assert(gob.NewEncoder(buffer).Encode(decoded), IsNil)
+	legacy = legacyState{}
+	received := State{}
+	c.Assert(gob.NewDecoder(bytes.NewBuffer(buffer.Bytes())).Decode(&legacy), IsNil)
+	c.Assert(gob.NewDecoder(buffer).Decode(&received), IsNil)
+	c.Check(legacy, Equals, legacyState{Name: "day", Temperature: 26, Humidity: 60, Wind: 100, VisibleLight: 30, UVLight: 10})
+	c.Check(s.blueLightChannel.Value(received), Equals, Light(50))
+}
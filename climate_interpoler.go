@@ -4,6 +4,7 @@ import (
 	"fmt"
 	"math"
 	"sort"
+	"strings"
 	"time"
 )
 
@@ -15,14 +16,13 @@ func SanitizeUnit(u BoundedUnit) float64 {
 }
 
 func SanitizeState(s State) State {
-	return State{
-		Name:         s.Name,
-		Temperature:  Temperature(SanitizeUnit(s.Temperature)),
-		Humidity:     Humidity(SanitizeUnit(s.Humidity)),
-		Wind:         Wind(SanitizeUnit(s.Wind)),
-		VisibleLight: Light(SanitizeUnit(s.VisibleLight)),
-		UVLight:      Light(SanitizeUnit(s.UVLight)),
+	res := State{Name: s.Name, HumidityTarget: s.HumidityTarget}
+	for _, c := range channels {
+		c.set(&res, SanitizeUnit(c.Value(s)))
 	}
+	// reports the humidity the target achieves
+	res.Humidity = Humidity(SanitizeUnit(s.EffectiveHumidity()))
+	return res
 }
 
 type Interpolation interface {
@@ -33,12 +33,26 @@ type Interpolation interface {
 
 type staticClimate State
 
-func (s *staticClimate) State(time.Time) State {
-	return State(*s)
+func (s *staticClimate) State(t time.Time) State {
+	return State(*s).At(t)
 }
 
 func (s *staticClimate) String() string {
-	return fmt.Sprintf("static state: %+v", State(*s))
+	drift := ""
+	if s.Drift != nil {
+		drift = " Drift:" + s.Drift.String()
+	}
+	if s.HumidityTarget != nil {
+		drift += " " + s.HumidityTarget.String()
+	}
+	extra := ""
+	for _, c := range channels[len(builtinChannels):] {
+		if v := c.Value(State(*s)); IsUndefined(v) == false {
+			extra += fmt.Sprintf(" %s:%v", c.Name, v.Value())
+		}
+	}
+	return fmt.Sprintf("static state: {Name:%s Temperature:%v Humidity:%v Wind:%v VisibleLight:%v UVLight:%v%s%s}",
+		s.Name, s.Temperature, s.Humidity, s.Wind, s.VisibleLight, s.UVLight, extra, drift)
 }
 
 func (s *staticClimate) End() *State {
@@ -49,6 +63,7 @@ type climateTransition struct {
 	start    time.Time
 	from, to State
 	duration time.Duration
+	curve    TransitionCurve
 }
 
 func interpolate(from, to, completion float64) float64 {
@@ -64,15 +79,12 @@ func interpolate(from, to, completion float64) float64 {
 }
 
 func interpolateState(from, to State, completion float64) State {
-	return State{
-		Name:         fmt.Sprintf("%s to %s", from.Name, to.Name),
-		Temperature:  Temperature(interpolate(from.Temperature.Value(), to.Temperature.Value(), completion)),
-		Humidity:     Humidity(interpolate(from.Humidity.Value(), to.Humidity.Value(), completion)),
-		Wind:         Wind(interpolate(from.Wind.Value(), to.Wind.Value(), completion)),
-		VisibleLight: Light(interpolate(from.VisibleLight.Value(), to.VisibleLight.Value(), completion)),
-		UVLight:      Light(interpolate(from.UVLight.Value(), to.UVLight.Value(), completion)),
+	res := State{Name: fmt.Sprintf("%s to %s", from.Name, to.Name)}
+	for _, c := range channels {
+		c.set(&res, c.interpolate(from, to, completion))
 	}
-
+	res.Humidity, res.HumidityTarget = interpolateHumidity(from, to, completion)
+	return res
 }
 
 func (i *climateTransition) State(t time.Time) State {
@@ -82,21 +94,59 @@ func (i *climateTransition) State(t time.Time) State {
 	} else if ellapsed > i.duration {
 		ellapsed = i.duration
 	}
-	completion := float64(ellapsed.Seconds()) / float64(i.duration.Seconds())
-	return interpolateState(i.from, i.to, completion)
+	completion := 1.0
+	if i.duration > 0 {
+		completion = float64(ellapsed.Seconds()) / float64(i.duration.Seconds())
+	}
+	return interpolateState(i.from.At(t), i.to.At(t), i.curve.Ease(completion))
 }
 
 func (i *climateTransition) String() string {
-	return fmt.Sprintf("transition from '%s' to '%s' in %s at %s", i.from.Name, i.to.Name, i.duration, i.start)
+	curve := ""
+	if i.curve != LinearCurve {
+		curve = fmt.Sprintf(" with %s curve", i.curve)
+	}
+	return fmt.Sprintf("transition from '%s' to '%s' in %s%s at %s", i.from.Name, i.to.Name, i.duration, curve, i.start)
 }
 
 func (t *climateTransition) End() *State {
-	state := SanitizeState(t.to)
+	state := SanitizeState(t.to.At(t.start.Add(t.duration)))
 	return &state
 }
 
+// TransitionDescription describes a scheduled transition between two
+// states.
+type TransitionDescription struct {
+	From, To string
+	Start    time.Time
+	Duration time.Duration
+}
+
+// DescribeTransition returns the description of i, if it is a
+// transition.
+func DescribeTransition(i Interpolation) (TransitionDescription, bool) {
+	if shifted, ok := i.(*shiftedInterpolation); ok == true {
+		res, ok := DescribeTransition(shifted.interpolation)
+		res.Start = res.Start.Add(shifted.offset)
+		return res, ok
+	}
+	t, ok := i.(*climateTransition)
+	if ok == false {
+		return TransitionDescription{}, false
+	}
+	return TransitionDescription{
+		From:     t.from.Name,
+		To:       t.to.Name,
+		Start:    t.start,
+		Duration: t.duration,
+	}, true
+}
+
 type ClimateInterpoler interface {
 	CurrentInterpolation(t time.Time) (Interpolation, time.Time, Interpolation)
+	// ExperimentEnd returns when the experiment ends, if it is
+	// finite.
+	ExperimentEnd() (time.Time, bool)
 }
 
 type computedState struct {
@@ -110,7 +160,14 @@ type climateInterpolation struct {
 	previous         *computedState
 	states           map[string]*computedState
 	currentTime      time.Time
+	location         *time.Location
+	latitude         float64
+	longitude        float64
+	weather          *Weather
 	year, month, day int
+
+	end      *time.Time
+	endState *computedState
 }
 
 type computedTransition struct {
@@ -132,11 +189,116 @@ func (l computedTransitionList) Swap(i, j int) {
 	l[j], l[i] = l[i], l[j]
 }
 
-func (i *climateInterpolation) computeTransitions(t time.Time, forward bool) []computedTransition {
-	//gets t date
-	y, m, d := t.Date()
+// wallClock returns the instant when the clocks of loc show the
+// given date and time. When the time does not exist because of a
+// daylight saving time gap, the time is shifted forward by the length
+// of the gap (i.e. 02:30 becomes 03:30 for a one hour gap). When the
+// time happens twice because of an overlap, the first occurence is
+// returned.
+func wallClock(y int, m time.Month, d int, hour, minute int, loc *time.Location) time.Time {
+	naive := time.Date(y, m, d, hour, minute, 0, 0, time.UTC)
+	_, offsetBefore := naive.Add(-24 * time.Hour).In(loc).Zone()
+	_, offsetAfter := naive.Add(24 * time.Hour).In(loc).Zone()
+	offsets := []int{offsetBefore, offsetAfter}
+	if offsetAfter > offsetBefore {
+		offsets = []int{offsetAfter, offsetBefore}
+	}
+	for _, offset := range offsets {
+		candidate := naive.Add(-time.Duration(offset) * time.Second)
+		cy, cm, cd := candidate.In(loc).Date()
+		ch, cmin, _ := candidate.In(loc).Clock()
+		if cy == y && cm == m && cd == d && ch == hour && cmin == minute {
+			return candidate
+		}
+	}
+	return naive.Add(-time.Duration(offsetBefore) * time.Second)
+}
 
+// maxRecurrenceSearch is the maximal number of days to look for the
+// next or previous occurence of a recurring transition.
+const maxRecurrenceSearch = 366
+
+// date returns the date of t in the interpolation location.
+func (i *climateInterpolation) date(t time.Time) time.Time {
+	y, m, d := t.In(i.location).Date()
+	return time.Date(y, m, d, 0, 0, 0, 0, time.UTC)
+}
+
+// dayIndex returns the number of days between the experiment start
+// and date, as returned by date().
+func (i *climateInterpolation) dayIndex(date time.Time) int {
+	reference := time.Date(i.year, time.Month(i.month), i.day, 0, 0, 0, 0, time.UTC)
+	return int(date.Sub(reference).Hours() / 24)
+}
+
+// triggerOn returns when tr will trigger on date, as returned by
+// date(). It returns false if the transition cannot trigger on that
+// date.
+func (i *climateInterpolation) triggerOn(tr Transition, date time.Time) (time.Time, bool) {
+	y, m, d := date.Date()
+	original := tr.To
+	if len(tr.variantOf) > 0 {
+		original = tr.variantOf
+	}
+	if i.weather.target(original, i.dayIndex(date)+1) != tr.To {
+		return time.Time{}, false
+	}
+	if tr.Recurrence != nil && tr.Recurrence.Matches(i.dayIndex(date)+1, date.Weekday()) == false {
+		return time.Time{}, false
+	}
+	if tr.SolarEvent != NoSolarEvent {
+		event, ok := SolarEventTime(tr.SolarEvent, y, m, d, i.latitude, i.longitude)
+		if ok == false {
+			return time.Time{}, false
+		}
+		return event.Add(tr.SolarOffset), true
+	}
+	trigger := wallClock(y, m, d, tr.Start.Hour(), tr.Start.Minute(), i.location)
+	return trigger.Add(tr.StartTimeDelta * time.Duration(i.dayIndex(date))), true
+}
+
+// dayTrigger returns when a transition with a Day field triggers.
+func (i *climateInterpolation) dayTrigger(tr Transition) (time.Time, bool) {
+	reference := time.Date(i.year, time.Month(i.month), i.day, 0, 0, 0, 0, time.UTC)
+	return i.triggerOn(tr, reference.AddDate(0, 0, tr.Day-1))
+}
+
+// recurringTrigger returns the next (forward) or previous (backward)
+// trigger time of a recurring transition relatively to t.
+func (i *climateInterpolation) recurringTrigger(tr Transition, t time.Time, forward bool) (time.Time, bool) {
+	date := i.date(t)
+	if forward == true {
+		for k := -1; k <= maxRecurrenceSearch; k++ {
+			current := date.AddDate(0, 0, k)
+			if tr.Recurrence != nil && tr.Recurrence.ToDay > 0 && i.dayIndex(current)+1 > tr.Recurrence.ToDay {
+				break
+			}
+			trigger, ok := i.triggerOn(tr, current)
+			if ok == true && trigger.Before(t) == false {
+				return trigger, true
+			}
+		}
+	} else {
+		for k := 1; k >= -maxRecurrenceSearch; k-- {
+			current := date.AddDate(0, 0, k)
+			if tr.Recurrence != nil && tr.Recurrence.FromDay > 0 && i.dayIndex(current)+1 < tr.Recurrence.FromDay {
+				break
+			}
+			trigger, ok := i.triggerOn(tr, current)
+			if ok == true && trigger.After(t) == false {
+				return trigger, true
+			}
+		}
+	}
+	return time.Time{}, false
+}
+
+func (i *climateInterpolation) computeTransitions(t time.Time, forward bool) []computedTransition {
 	res := map[time.Time][]computedTransition{}
+	// others are the transitions walking backward from another state
+	// than the one we come from, only considered if none of the
+	// transitions from that state occurred.
+	others := map[time.Time][]computedTransition{}
 	var transitions []Transition
 	if forward == true {
 		transitions = i.current.transitionForward
@@ -144,46 +306,103 @@ func (i *climateInterpolation) computeTransitions(t time.Time, forward bool) []c
 		transitions = i.current.transitionBackward
 	}
 	for _, tr := range transitions {
-		if forward == false && i.previous != nil && tr.From != i.previous.Name {
-			continue
+		candidates := res
+		// transitions restricted to some days are always considered,
+		// as they have priority when they occur.
+		if forward == false && i.previous != nil && tr.From != i.previous.Name && tr.specificity() == 0 && tr.Priority == 0 && i.weather.involves(tr.From) == false {
+			candidates = others
 		}
 		if tr.Day != 0 {
-			trigger := tr.Start.AddDate(i.year, i.month-1, i.day-1+tr.Day-1)
+			trigger, ok := i.dayTrigger(tr)
+			if ok == false {
+				continue
+			}
 			if (forward == true && trigger.Before(t)) || (forward == false && trigger.After(t)) {
 				continue
 			}
-			res[trigger] = append(res[trigger], computedTransition{trigger, tr})
+			if forward == false && i.outranked(tr, trigger) == true {
+				continue
+			}
+			candidates[trigger] = append(candidates[trigger], computedTransition{trigger, tr})
 		} else {
-			tUTC := t.UTC()
-			dayEllapsed := int(tUTC.Sub(time.Date(i.year, time.Month(i.month), i.day, tUTC.Hour(), tUTC.Minute(), tUTC.Second(), tUTC.Nanosecond(), time.UTC)) / (24 * time.Hour))
-			trigger := tr.Start.AddDate(y, int(m)-1, d-1).Add(tr.StartTimeDelta * time.Duration(dayEllapsed))
-			if forward == true && trigger.Before(t) {
-				trigger = trigger.AddDate(0, 0, 1)
+			trigger, ok := i.recurringTrigger(tr, t, forward)
+			// walking backward, only the transitions which occurred
+			// are considered.
+			for k := 0; ok == true && forward == false && i.outranked(tr, trigger) == true; k++ {
+				if k >= maxRecurrenceSearch {
+					ok = false
+					break
+				}
+				trigger, ok = i.recurringTrigger(tr, trigger.Add(-time.Second), false)
 			}
-			if forward == false && trigger.After(t) {
-				trigger = trigger.AddDate(0, 0, -1)
+			if ok == false {
+				continue
 			}
-			res[trigger] = append(res[trigger], computedTransition{trigger, tr})
+			candidates[trigger] = append(candidates[trigger], computedTransition{trigger, tr})
 		}
 	}
+	if len(res) == 0 {
+		res = others
+	}
 
-	//non - recuring tranistion have priority on reccurent one
+	// only the transition with the highest rank occurs. Transitions
+	// of the same rank leaving the same state are rejected by
+	// checkCollisions, the first declared one is used otherwise.
 	resList := make([]computedTransition, 0, len(res))
 	for _, t := range res {
 		if len(t) == 0 {
 			continue
 		}
-		if len(t) == 1 || t[0].transition.Day != 0 {
-			resList = append(resList, t[0])
-			continue
+		if forward == false {
+			t = i.plausibleTransitions(t)
+		}
+		best := t[0]
+		for _, ct := range t[1:] {
+			if ct.transition.outranks(best.transition) {
+				best = ct
+			}
 		}
-		resList = append(resList, t[1])
-		//we simply ignore multiple recurring transition
+		resList = append(resList, best)
 	}
 
 	return resList
 }
 
+// outranked returns true if another transition leaving the same
+// state as tr at trigger outranks it.
+func (i *climateInterpolation) outranked(tr Transition, trigger time.Time) bool {
+	date := i.date(trigger)
+	for _, o := range i.states[tr.From].transitionForward {
+		if o == tr || o.outranks(tr) == false || (o.Day != 0 && o.Day != i.dayIndex(date)+1) {
+			continue
+		}
+		if t, ok := i.triggerOn(o, date); ok == true && t.Equal(trigger) {
+			return true
+		}
+	}
+	return false
+}
+
+// plausibleTransitions removes from transitions occuring at the same
+// time the ones leaving a state that the weather replaced that day,
+// unless none would remain.
+func (i *climateInterpolation) plausibleTransitions(transitions []computedTransition) []computedTransition {
+	if len(transitions) < 2 || i.weather == nil {
+		return transitions
+	}
+	res := make([]computedTransition, 0, len(transitions))
+	for _, ct := range transitions {
+		day := i.dayIndex(i.date(ct.time)) + 1
+		if i.weather.plausible(ct.transition.From, day) == true {
+			res = append(res, ct)
+		}
+	}
+	if len(res) == 0 {
+		return transitions
+	}
+	return res
+}
+
 func (i *climateInterpolation) nextForwardTransition(t time.Time) (computedTransition, bool) {
 	orderedTransitions := i.computeTransitions(t, true)
 	if len(orderedTransitions) == 0 {
@@ -230,7 +449,33 @@ func (i *climateInterpolation) walkTo(t time.Time) (prev, next computedTransitio
 	}
 }
 
+func (i *climateInterpolation) ExperimentEnd() (time.Time, bool) {
+	if i.end == nil {
+		return time.Time{}, false
+	}
+	return *i.end, true
+}
+
+// endInterpolation is the Interpolation after the experiment end.
+func (i *climateInterpolation) endInterpolation() Interpolation {
+	if i.endState == nil {
+		return &stoppedClimate{since: *i.end}
+	}
+	return (*staticClimate)(&(i.endState.State))
+}
+
 func (i *climateInterpolation) CurrentInterpolation(t time.Time) (Interpolation, time.Time, Interpolation) {
+	if i.end != nil && t.Before(*i.end) == false {
+		return i.endInterpolation(), time.Time{}, nil
+	}
+	currentI, nextTime, nextI := i.currentInterpolation(t)
+	if i.end != nil && (nextI == nil || nextTime.After(*i.end)) {
+		return currentI, *i.end, i.endInterpolation()
+	}
+	return currentI, nextTime, nextI
+}
+
+func (i *climateInterpolation) currentInterpolation(t time.Time) (Interpolation, time.Time, Interpolation) {
 	prevT, nextT, prevOK, nextOK := i.walkTo(t.UTC())
 	var currentI, nextI Interpolation
 	var nextTime time.Time
@@ -242,6 +487,7 @@ func (i *climateInterpolation) CurrentInterpolation(t time.Time) (Interpolation,
 				from:     i.current.State,
 				to:       i.states[nextT.transition.To].State,
 				duration: nextT.transition.Duration,
+				curve:    nextT.transition.Curve,
 			}
 			nextTime = nextT.time
 		}
@@ -251,6 +497,7 @@ func (i *climateInterpolation) CurrentInterpolation(t time.Time) (Interpolation,
 			from:     i.states[prevT.transition.From].State,
 			to:       i.current.State,
 			duration: prevT.transition.Duration,
+			curve:    prevT.transition.Curve,
 		}
 		nextI = (*staticClimate)(&(i.current.State))
 		nextTime = prevT.time.Add(prevT.transition.Duration)
@@ -258,18 +505,220 @@ func (i *climateInterpolation) CurrentInterpolation(t time.Time) (Interpolation,
 	return currentI, nextTime, nextI
 }
 
+// sameDay returns the first experiment day where both a and b, two
+// transitions with either a Day or a Recurrence, would occur.
+func (i *climateInterpolation) sameDay(a, b Transition) (time.Time, bool) {
+	reference := time.Date(i.year, time.Month(i.month), i.day, 0, 0, 0, 0, time.UTC)
+	matches := func(t Transition, date time.Time) bool {
+		day := i.dayIndex(date) + 1
+		if t.Day != 0 {
+			return t.Day == day
+		}
+		return t.Recurrence.Matches(day, date.Weekday())
+	}
+	if a.Day != 0 || b.Day != 0 {
+		day := a.Day
+		if day == 0 {
+			day = b.Day
+		}
+		date := reference.AddDate(0, 0, day-1)
+		return date, matches(a, date) && matches(b, date)
+	}
+	for k := 0; k < maxRecurrenceSearch; k++ {
+		date := reference.AddDate(0, 0, k)
+		if matches(a, date) && matches(b, date) {
+			return date, true
+		}
+	}
+	return time.Time{}, false
+}
+
+// checkShadowing returns an error if a and b, two transitions from
+// the same state restricted to some days, occurs the same day at a
+// different time: the last one would never occur.
+func (i *climateInterpolation) checkShadowing(a, b Transition) error {
+	if a.specificity() == 0 || b.specificity() == 0 {
+		return nil
+	}
+	date, ok := i.sameDay(a, b)
+	if ok == false {
+		return nil
+	}
+	startA, okA := i.triggerOn(a, date)
+	startB, okB := i.triggerOn(b, date)
+	if okA == false || okB == false {
+		return nil
+	}
+	if startA.Before(startB) {
+		return fmt.Errorf("%s is shadowed by %s", b, a)
+	} else if startB.Before(startA) {
+		return fmt.Errorf("%s is shadowed by %s", a, b)
+	}
+	return nil
+}
+
+// transitionCollision is a set of transitions leaving the same state
+// at the same time, where none outranks the others.
+type transitionCollision struct {
+	transitions []Transition
+	from        string
+	time        time.Time
+	day         int
+}
+
+func (c transitionCollision) Error() string {
+	names := make([]string, 0, len(c.transitions))
+	for _, t := range c.transitions {
+		names = append(names, t.String())
+	}
+	return fmt.Sprintf("%s both start from '%s' at %s on day %d with the same priority: set a different priority to choose which one occurs",
+		strings.Join(names, " and "), c.from, c.time.Format("15:04"), c.day)
+}
+
+// collisions returns the first collision of each set of colliding
+// transitions, over a year of experiment.
+func (i *climateInterpolation) collisions(states []State) []transitionCollision {
+	reference := time.Date(i.year, time.Month(i.month), i.day, 0, 0, 0, 0, time.UTC)
+	var res []transitionCollision
+	reported := map[string]bool{}
+	for _, s := range states {
+		transitions := i.states[s.Name].transitionForward
+		for k := 0; k < maxRecurrenceSearch; k++ {
+			date := reference.AddDate(0, 0, k)
+			byTime := map[time.Time][]Transition{}
+			var triggers []time.Time
+			for _, tr := range transitions {
+				// weather variants are never triggered with the
+				// transition they replace.
+				if len(tr.variantOf) > 0 || (tr.Day != 0 && tr.Day != k+1) {
+					continue
+				}
+				trigger, ok := i.triggerOn(tr, date)
+				if ok == false {
+					continue
+				}
+				if _, ok := byTime[trigger]; ok == false {
+					triggers = append(triggers, trigger)
+				}
+				byTime[trigger] = append(byTime[trigger], tr)
+			}
+			for _, trigger := range triggers {
+				colliding := byTime[trigger]
+				best := colliding[0]
+				for _, tr := range colliding[1:] {
+					if tr.outranks(best) {
+						best = tr
+					}
+				}
+				var same []Transition
+				for _, tr := range colliding {
+					if best.outranks(tr) == false {
+						same = append(same, tr)
+					}
+				}
+				if len(same) < 2 {
+					continue
+				}
+				key := fmt.Sprintf("%v", same)
+				if reported[key] == true {
+					continue
+				}
+				reported[key] = true
+				res = append(res, transitionCollision{
+					transitions: same,
+					from:        s.Name,
+					time:        trigger.In(i.location),
+					day:         k + 1,
+				})
+			}
+		}
+	}
+	return res
+}
+
+// checkCollisions returns an error if transitions leaving the same
+// state at the same time cannot be ordered by their priority.
+func (i *climateInterpolation) checkCollisions(states []State) error {
+	if collisions := i.collisions(states); len(collisions) > 0 {
+		return collisions[0]
+	}
+	return nil
+}
+
+// NewClimateInterpoler creates a ClimateInterpoler for states and
+// transitions in UTC, starting the experiment at reference.
 func NewClimateInterpoler(states []State, transitions []Transition, reference time.Time) (ClimateInterpoler, error) {
+	res, err := newClimateInterpolation(ZoneClimate{States: states, Transitions: transitions}, reference)
+	if err != nil {
+		return nil, err
+	}
+	if err := res.checkCollisions(states); err != nil {
+		return nil, err
+	}
+	return res, nil
+}
+
+// NewZoneClimateInterpoler creates a ClimateInterpoler for a
+// ZoneClimate, taking into account all zone-level settings, and
+// starting the experiment at reference.
+func NewZoneClimateInterpoler(climate ZoneClimate, reference time.Time) (ClimateInterpoler, error) {
+	if climate.follows() == true {
+		return nil, fmt.Errorf("zone follows '%s': it should be resolved with SeasonFile.ResolveZones", climate.Follow)
+	}
+	res, err := newClimateInterpolation(climate, reference)
+	if err != nil {
+		return nil, err
+	}
+	if err := res.checkCollisions(climate.States); err != nil {
+		return nil, err
+	}
+	if climate.TimeOffset == 0 {
+		return res, nil
+	}
+	return &shiftedInterpoler{interpoler: res, offset: climate.TimeOffset}, nil
+}
+
+func newClimateInterpolation(climate ZoneClimate, reference time.Time) (*climateInterpolation, error) {
+	states, transitions := climate.States, climate.Transitions
 	if len(states) == 0 {
 		return nil, fmt.Errorf("climate interpolation needs at least one state")
 	}
-	y, m, d := reference.Date()
+	location, err := climate.Location()
+	if err != nil {
+		return nil, err
+	}
+	if err := climate.checkSite(); err != nil {
+		return nil, err
+	}
+	if err := climate.checkAlarms(); err != nil {
+		return nil, err
+	}
+	y, m, d := reference.In(location).Date()
 	res := &climateInterpolation{
 		states:      make(map[string]*computedState),
+		location:    location,
 		year:        y,
 		month:       int(m),
 		day:         d,
 		currentTime: reference.AddDate(0, 0, -2),
 	}
+	if climate.Latitude != nil && climate.Longitude != nil {
+		res.latitude = *climate.Latitude
+		res.longitude = *climate.Longitude
+	}
+	if climate.Weather != nil {
+		if err := climate.Weather.Check(states); err != nil {
+			return nil, err
+		}
+		res.weather = climate.Weather
+	}
+	if climate.End != nil {
+		end, err := climate.End.Resolve(reference, location)
+		if err != nil {
+			return nil, err
+		}
+		res.end = &end
+	}
 	for _, s := range states {
 		if _, ok := res.states[s.Name]; ok == true {
 			return nil, fmt.Errorf("Cannot redefine state '%s'", s.Name)
@@ -298,25 +747,52 @@ func NewClimateInterpoler(states []State, transitions []Transition, reference ti
 		from.transitionForward = append(from.transitionForward, t)
 	}
 
+	if climate.End != nil && len(climate.End.State) > 0 {
+		endState, ok := res.states[climate.End.State]
+		if ok == false {
+			return nil, fmt.Errorf("Undefined state '%s' in experiment end", climate.End.State)
+		}
+		res.endState = endState
+	}
+
+	// transitions entering a state with weather variants may reach
+	// the variant instead.
+	if res.weather != nil {
+		for _, v := range res.weather.Variants {
+			for _, t := range transitions {
+				if t.To != v.State {
+					continue
+				}
+				t.To = v.Variant
+				t.variantOf = v.State
+				res.states[t.To].transitionBackward = append(res.states[t.To].transitionBackward, t)
+				res.states[t.From].transitionForward = append(res.states[t.From].transitionForward, t)
+			}
+		}
+	}
+
 	// computes the states
+	origin := wallClock(y, m, d, 0, 0, location)
 	for _, s := range states {
 		cs := res.states[s.Name]
+		drift := cs.State.Drift
 		if len(cs.transitionForward) != 0 {
-			cs.State = interpolateState(cs.State, res.states[cs.transitionForward[0].To].State, 0)
+			to := res.states[cs.transitionForward[0].To].State
+			drift = drift.inherit(cs.State, to)
+			cs.State = interpolateState(cs.State, to, 0)
 			cs.State.Name = s.Name
 		}
 		if len(cs.transitionBackward) != 0 {
-			cs.State = interpolateState(res.states[cs.transitionBackward[0].From].State, cs.State, 1)
+			from := res.states[cs.transitionBackward[0].From].State
+			drift = drift.inherit(cs.State, from)
+			cs.State = interpolateState(from, cs.State, 1)
 			cs.State.Name = s.Name
 		}
+		cs.State.Drift = drift.bind(origin, res.weather)
 		for i, trA := range cs.transitionForward {
 			for _, trB := range cs.transitionForward[i:] {
-				if trA.Day != 0 && trB.Day == trA.Day {
-					if trA.Start.Before(trB.Start) {
-						return nil, fmt.Errorf("%s is shadowed by %s", trB, trA)
-					} else if trB.Start.Before(trA.Start) {
-						return nil, fmt.Errorf("%s is shadowed by %s", trA, trB)
-					}
+				if err := res.checkShadowing(trA, trB); err != nil {
+					return nil, err
 				}
 			}
 
@@ -1,7 +1,9 @@
 package zeus
 
 import (
+	"math"
 	reflect "reflect"
+	"regexp"
 	"time"
 
 	. "gopkg.in/check.v1"
@@ -36,6 +38,16 @@ func (s *ClimateInterpolerSuite) TestInterpolationFormat(c *C) {
 			},
 			"transition from 'day' to 'night' in 30m0s at 2019-01-01 10:00:00 +0000 UTC",
 		},
+		{
+			&climateTransition{
+				from:     State{Name: "night"},
+				to:       State{Name: "day"},
+				duration: time.Hour,
+				start:    time.Date(2019, 1, 1, 6, 00, 0, 0, time.UTC),
+				curve:    CosineCurve,
+			},
+			"transition from 'night' to 'day' in 1h0m0s with cosine curve at 2019-01-01 06:00:00 +0000 UTC",
+		},
 	}
 
 	for _, d := range testdata {
@@ -59,6 +71,21 @@ func (s *ClimateInterpolerSuite) TestStaticState(c *C) {
 	}
 }
 
+func (s *ClimateInterpolerSuite) TestDescribeTransition(c *C) {
+	start := time.Date(2021, 3, 1, 6, 0, 0, 0, time.UTC)
+	d, ok := DescribeTransition(&climateTransition{
+		start:    start,
+		from:     State{Name: "night"},
+		to:       State{Name: "day"},
+		duration: 30 * time.Minute,
+	})
+	c.Check(ok, Equals, true)
+	c.Check(d, Equals, TransitionDescription{From: "night", To: "day", Start: start, Duration: 30 * time.Minute})
+
+	_, ok = DescribeTransition(&staticClimate{Name: "day"})
+	c.Check(ok, Equals, false)
+}
+
 func (s *ClimateInterpolerSuite) TestInterpolation(c *C) {
 
 	i := climateTransition{
@@ -99,6 +126,288 @@ func (s *ClimateInterpolerSuite) TestInterpolation(c *C) {
 
 }
 
+func (s *ClimateInterpolerSuite) TestInterpolationCurve(c *C) {
+	i := climateTransition{
+		from:     State{Name: "a", Temperature: 20, Humidity: UndefinedHumidity, Wind: 0, VisibleLight: 0, UVLight: 0},
+		to:       State{Name: "b", Temperature: 30, Humidity: UndefinedHumidity, Wind: 100, VisibleLight: 100, UVLight: 0},
+		duration: 40 * time.Minute,
+		start:    time.Date(2019, 1, 1, 6, 0, 0, 0, time.UTC),
+	}
+
+	testdata := []struct {
+		curve    TransitionCurve
+		d        time.Duration
+		expected Temperature
+	}{
+		{LinearCurve, 10 * time.Minute, 22.5},
+		{CosineCurve, 20 * time.Minute, 25},
+		{StepStartCurve, 1 * time.Minute, 30},
+		{StepEndCurve, 39 * time.Minute, 20},
+		{StepEndCurve, 40 * time.Minute, 30},
+	}
+
+	for _, d := range testdata {
+		i.curve = d.curve
+		result := i.State(i.start.Add(d.d)).Temperature
+		c.Check(math.Abs(float64(result-d.expected)) < 1e-6, Equals, true, Commentf("%s after %s: got %f expected %f", d.curve, d.d, result, d.expected))
+	}
+
+	states := []State{{Name: "night", Temperature: 20}, {Name: "day", Temperature: 30}}
+	transitions := []Transition{
+		{From: "night", To: "day", Start: time.Date(0, 1, 1, 6, 0, 0, 0, time.UTC), Duration: time.Hour, Curve: SigmoidCurve},
+		{From: "day", To: "night", Start: time.Date(0, 1, 1, 18, 0, 0, 0, time.UTC), Duration: time.Hour},
+	}
+	basedate := time.Date(2019, 1, 2, 0, 0, 0, 0, time.UTC)
+	interpoler, err := NewClimateInterpoler(states, transitions, basedate)
+	c.Assert(err, IsNil)
+	current, _, _ := interpoler.CurrentInterpolation(basedate.Add(6*time.Hour + 15*time.Minute))
+	if c.Check(current, FitsTypeOf, &climateTransition{}) == true {
+		c.Check(current.(*climateTransition).curve, Equals, SigmoidCurve)
+	}
+	_, _, next := interpoler.CurrentInterpolation(basedate.Add(12 * time.Hour))
+	if c.Check(next, FitsTypeOf, &climateTransition{}) == true {
+		c.Check(next.(*climateTransition).curve, Equals, LinearCurve)
+	}
+}
+
+func (s *ClimateInterpolerSuite) TestWallClock(c *C) {
+	paris, err := time.LoadLocation("Europe/Paris")
+	c.Assert(err, IsNil)
+	testdata := []struct {
+		y        int
+		m        time.Month
+		d        int
+		h, min   int
+		expected time.Time
+	}{
+		// standard time, UTC+1
+		{2019, 3, 30, 2, 30, time.Date(2019, 3, 30, 1, 30, 0, 0, time.UTC)},
+		// 02:30 does not exist, shifted to 03:30 CEST
+		{2019, 3, 31, 2, 30, time.Date(2019, 3, 31, 1, 30, 0, 0, time.UTC)},
+		{2019, 3, 31, 3, 0, time.Date(2019, 3, 31, 1, 0, 0, 0, time.UTC)},
+		// daylight saving time, UTC+2
+		{2019, 4, 1, 2, 30, time.Date(2019, 4, 1, 0, 30, 0, 0, time.UTC)},
+		// 02:30 happens twice, the first occurence is used
+		{2019, 10, 27, 2, 30, time.Date(2019, 10, 27, 0, 30, 0, 0, time.UTC)},
+		{2019, 10, 27, 3, 0, time.Date(2019, 10, 27, 2, 0, 0, 0, time.UTC)},
+		{2019, 10, 28, 2, 30, time.Date(2019, 10, 28, 1, 30, 0, 0, time.UTC)},
+	}
+	for _, d := range testdata {
+		result := wallClock(d.y, d.m, d.d, d.h, d.min, paris)
+		c.Check(result.Equal(d.expected), Equals, true, Commentf("%d-%02d-%02d %02d:%02d: got %s, expected %s", d.y, d.m, d.d, d.h, d.min, result, d.expected))
+	}
+	c.Check(wallClock(2019, 3, 31, 2, 30, time.UTC), Equals, time.Date(2019, 3, 31, 2, 30, 0, 0, time.UTC))
+}
+
+func (s *ClimateInterpolerSuite) TestTimezone(c *C) {
+	climate := ZoneClimate{
+		Timezone: "Europe/Paris",
+		States:   []State{{Name: "night", Temperature: 20}, {Name: "day", Temperature: 26}},
+		Transitions: []Transition{
+			{From: "night", To: "day", Start: time.Date(0, 1, 1, 2, 30, 0, 0, time.UTC), Duration: 10 * time.Minute},
+			{From: "day", To: "night", Start: time.Date(0, 1, 1, 12, 0, 0, 0, time.UTC), Duration: 10 * time.Minute},
+			{From: "night", To: "day", Start: time.Date(0, 1, 1, 2, 0, 0, 0, time.UTC), Duration: 10 * time.Minute, Day: 5},
+		},
+	}
+	i, err := NewZoneClimateInterpoler(climate, time.Date(2019, 3, 29, 13, 0, 0, 0, time.UTC))
+	c.Assert(err, IsNil)
+
+	testdata := []struct {
+		at, next time.Time
+	}{
+		{time.Date(2019, 3, 29, 22, 0, 0, 0, time.UTC), time.Date(2019, 3, 30, 1, 30, 0, 0, time.UTC)},
+		{time.Date(2019, 3, 30, 6, 0, 0, 0, time.UTC), time.Date(2019, 3, 30, 11, 0, 0, 0, time.UTC)},
+		{time.Date(2019, 3, 30, 22, 0, 0, 0, time.UTC), time.Date(2019, 3, 31, 1, 30, 0, 0, time.UTC)},
+		{time.Date(2019, 3, 31, 6, 0, 0, 0, time.UTC), time.Date(2019, 3, 31, 10, 0, 0, 0, time.UTC)},
+		{time.Date(2019, 3, 31, 22, 0, 0, 0, time.UTC), time.Date(2019, 4, 1, 0, 30, 0, 0, time.UTC)},
+		// day 5 transition at 02:00 CEST
+		{time.Date(2019, 4, 1, 22, 0, 0, 0, time.UTC), time.Date(2019, 4, 2, 0, 0, 0, 0, time.UTC)},
+	}
+	for _, d := range testdata {
+		_, next, nextInterpolation := i.CurrentInterpolation(d.at)
+		if c.Check(nextInterpolation, Not(IsNil), Commentf("at %s", d.at)) == true {
+			c.Check(next, Equals, d.next, Commentf("at %s", d.at))
+		}
+	}
+
+	climate.Timezone = "Mars/Olympus_Mons"
+	_, err = NewZoneClimateInterpoler(climate, time.Now())
+	c.Check(err, ErrorMatches, "invalid timezone 'Mars/Olympus_Mons': .*")
+	climate.Timezone = "Local"
+	_, err = NewZoneClimateInterpoler(climate, time.Now())
+	c.Check(err, ErrorMatches, "invalid timezone 'Local': an IANA timezone name is required")
+}
+
+func (s *ClimateInterpolerSuite) TestSolarTransitions(c *C) {
+	latitude, longitude := 51.51, -0.13
+	climate := ZoneClimate{
+		States: []State{{Name: "night", VisibleLight: 0}, {Name: "day", VisibleLight: 100}},
+		Transitions: []Transition{
+			{From: "night", To: "day", SolarEvent: Sunrise, Duration: 30 * time.Minute},
+			{From: "day", To: "night", SolarEvent: Sunset, SolarOffset: -30 * time.Minute, Duration: 30 * time.Minute},
+		},
+	}
+	_, err := NewZoneClimateInterpoler(climate, time.Now())
+	c.Check(err, ErrorMatches, ".* requires the zone latitude and longitude")
+
+	climate.Latitude = &latitude
+	climate.Longitude = &longitude
+	reference := time.Date(2021, 3, 1, 12, 0, 0, 0, time.UTC)
+	i, err := NewZoneClimateInterpoler(climate, reference)
+	c.Assert(err, IsNil)
+
+	var previousLength time.Duration
+	for day := 0; day < 60; day += 10 {
+		midnight := time.Date(2021, 3, 2+day, 0, 0, 0, 0, time.UTC)
+		y, m, d := midnight.Date()
+		sunrise, _ := SolarEventTime(Sunrise, y, m, d, latitude, longitude)
+		sunset, _ := SolarEventTime(Sunset, y, m, d, latitude, longitude)
+
+		current, next, _ := i.CurrentInterpolation(midnight)
+		c.Check(current, DeepEquals, (*staticClimate)(&climate.States[0]))
+		c.Check(next, Equals, sunrise)
+
+		_, next, _ = i.CurrentInterpolation(sunrise.Add(time.Hour))
+		c.Check(next, Equals, sunset.Add(-30*time.Minute))
+
+		length := sunset.Sub(sunrise)
+		c.Check(length > previousLength, Equals, true, Commentf("day length should increase in spring"))
+		previousLength = length
+	}
+
+	invalid := 91.0
+	climate.Latitude = &invalid
+	_, err = NewZoneClimateInterpoler(climate, reference)
+	c.Check(err, ErrorMatches, "invalid latitude 91.000000: should be in \\[-90,90\\]")
+}
+
+func (s *ClimateInterpolerSuite) TestRecurrence(c *C) {
+	at := func(h int) time.Time { return time.Date(0, 1, 1, h, 0, 0, 0, time.UTC) }
+	states := []State{{Name: "night"}, {Name: "day"}, {Name: "cloudy-day"}, {Name: "hot-day"}}
+	transitions := []Transition{
+		{From: "night", To: "day", Start: at(6)},
+		{From: "day", To: "night", Start: at(18)},
+		// returning transitions are restricted as well, so the
+		// interpoler can walk backward in time.
+		{From: "cloudy-day", To: "night", Start: at(18), Recurrence: &Recurrence{Weekdays: []time.Weekday{time.Monday}}},
+		{From: "hot-day", To: "night", Start: at(18), Recurrence: &Recurrence{Every: 3, FromDay: 10, ToDay: 20}},
+		{From: "night", To: "cloudy-day", Start: at(6), Recurrence: &Recurrence{Weekdays: []time.Weekday{time.Monday}}},
+		{From: "night", To: "hot-day", Start: at(6), Recurrence: &Recurrence{Every: 3, FromDay: 10, ToDay: 20}},
+	}
+	// 2021-03-01 is a monday, and day 1 of the experiment
+	reference := time.Date(2021, 3, 1, 0, 0, 0, 0, time.UTC)
+	i, err := NewClimateInterpoler(states, transitions, reference)
+	c.Assert(err, IsNil)
+
+	for day := 1; day <= 30; day++ {
+		noon := reference.AddDate(0, 0, day-1).Add(12 * time.Hour)
+		expected := "day"
+		if noon.Weekday() == time.Monday {
+			expected = "cloudy-day"
+		}
+		if day >= 10 && day <= 20 && (day-10)%3 == 0 {
+			expected = "hot-day"
+		}
+		current, _, _ := i.CurrentInterpolation(noon)
+		c.Check(current.State(noon).Name, Equals, expected, Commentf("day %d (%s)", day, noon.Weekday()))
+	}
+
+	// walking backward should give the same result
+	noon := reference.AddDate(0, 0, 12).Add(12 * time.Hour)
+	current, _, _ := i.CurrentInterpolation(noon)
+	c.Check(current.State(noon).Name, Equals, "hot-day")
+
+	shadowed := append([]Transition{}, transitions...)
+	shadowed[5].Start = at(7)
+	shadowed[5].Recurrence = &Recurrence{FromDay: 10, ToDay: 20}
+	_, err = NewClimateInterpoler(states, shadowed, reference)
+	c.Check(err, ErrorMatches, ".*Transition.*hot-day.* is shadowed by .*Transition.*cloudy-day.*")
+
+	shadowed = append([]Transition{}, transitions...)
+	shadowed = append(shadowed, Transition{From: "night", To: "day", Start: at(5), Day: 8})
+	_, err = NewClimateInterpoler(states, shadowed, reference)
+	c.Check(err, ErrorMatches, ".*Transition.*cloudy-day.* is shadowed by .*Transition.*OnDay: 8.*")
+
+	shadowed[len(shadowed)-1].Day = 9
+	_, err = NewClimateInterpoler(states, shadowed, reference)
+	c.Check(err, IsNil)
+}
+
+func (s *ClimateInterpolerSuite) TestPriority(c *C) {
+	at := func(h int) time.Time { return time.Date(0, 1, 1, h, 0, 0, 0, time.UTC) }
+	states := []State{{Name: "day"}, {Name: "night"}, {Name: "dusk"}, {Name: "storm"}}
+	transitions := []Transition{
+		{From: "night", To: "day", Start: at(6)},
+		{From: "dusk", To: "night", Start: at(20)},
+		{From: "storm", To: "night", Start: at(20)},
+		{From: "day", To: "night", Start: at(18)},
+		{From: "day", To: "dusk", Start: at(18), Priority: 1},
+	}
+	// 2021-03-01 is a monday, and day 1 of the experiment
+	reference := time.Date(2021, 3, 1, 0, 0, 0, 0, time.UTC)
+	evening := func(day int) time.Time { return reference.AddDate(0, 0, day-1).Add(19 * time.Hour) }
+	i, err := NewClimateInterpoler(states, transitions, reference)
+	c.Assert(err, IsNil)
+	current, _, _ := i.CurrentInterpolation(evening(1))
+	c.Check(current.State(evening(1)).Name, Equals, "dusk")
+
+	// priority is used before the specificity of transitions
+	withStorms := append(append([]Transition{}, transitions...),
+		Transition{From: "day", To: "storm", Start: at(18), Recurrence: &Recurrence{Weekdays: []time.Weekday{time.Friday}}},
+		Transition{From: "day", To: "storm", Start: at(18), Day: 3, Priority: 2},
+	)
+	i, err = NewClimateInterpoler(states, withStorms, reference)
+	c.Assert(err, IsNil)
+	for day, expected := range []string{"dusk", "dusk", "storm", "dusk", "dusk", "dusk"} {
+		current, _, _ := i.CurrentInterpolation(evening(day + 1))
+		c.Check(current.State(evening(day+1)).Name, Equals, expected, Commentf("day %d", day+1))
+	}
+	// outranked transitions are not considered walking backward
+	current, _, _ = i.CurrentInterpolation(evening(2))
+	c.Check(current.State(evening(2)).Name, Equals, "dusk")
+
+	collisions := []struct {
+		Transition Transition
+		Error      string
+	}{
+		{
+			Transition{From: "day", To: "storm", Start: at(18), Priority: 1},
+			"RecurringTransition{From: day, To: dusk, Start: 18:00, Duration: 0s, Priority: 1} and RecurringTransition{From: day, To: storm, Start: 18:00, Duration: 0s, Priority: 1} both start from 'day' at 18:00 on day 1 with the same priority: set a different priority to choose which one occurs",
+		},
+		{
+			Transition{From: "day", To: "storm", Start: at(18), Priority: 1, Recurrence: &Recurrence{Weekdays: []time.Weekday{time.Friday}}},
+			"",
+		},
+		{
+			Transition{From: "day", To: "storm", Start: at(18), Priority: 1, Day: 4},
+			"",
+		},
+	}
+	for _, d := range collisions {
+		_, err := NewClimateInterpoler(states, append(append([]Transition{}, transitions...), d.Transition), reference)
+		if len(d.Error) == 0 {
+			c.Check(err, IsNil)
+		} else {
+			c.Check(err, ErrorMatches, regexp.QuoteMeta(d.Error))
+		}
+	}
+
+	// recurring and day-specific transitions colliding
+	colliding := append(append([]Transition{}, transitions...),
+		Transition{From: "day", To: "storm", Start: at(18), Recurrence: &Recurrence{Weekdays: []time.Weekday{time.Friday}}, Priority: 1},
+		Transition{From: "day", To: "night", Start: at(18), Recurrence: &Recurrence{Every: 2}, Priority: 1},
+	)
+	_, err = NewClimateInterpoler(states, colliding, reference)
+	c.Check(err, ErrorMatches, ".*To: storm.* and .*To: night.* both start from 'day' at 18:00 on day 5 with the same priority.*")
+
+	colliding = append(append([]Transition{}, transitions...),
+		Transition{From: "day", To: "storm", Start: at(18), Day: 2, Priority: 1},
+		Transition{From: "day", To: "night", Start: at(18), Day: 2, Priority: 1},
+	)
+	_, err = NewClimateInterpoler(states, colliding, reference)
+	c.Check(err, ErrorMatches, ".*To: storm.*OnDay: 2.* and .*To: night.*OnDay: 2.* both start from 'day' at 18:00 on day 2 with the same priority.*")
+}
+
 func (s *ClimateInterpolerSuite) TestClimateInterpoler(c *C) {
 
 	definedDay := State{
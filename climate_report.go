@@ -29,6 +29,24 @@ func (r ClimateReport) Check() error {
 	return nil
 }
 
+// VaporPressureDeficit returns the vapour-pressure deficit in kPa
+// derived from the humidity and the main temperature of the report.
+func (r ClimateReport) VaporPressureDeficit() float64 {
+	if len(r.Temperatures) == 0 {
+		return math.NaN()
+	}
+	return VaporPressureDeficit(r.Temperatures[0], r.Humidity)
+}
+
+// AbsoluteHumidity returns the absolute humidity in g/m³ derived from
+// the humidity and the main temperature of the report.
+func (r ClimateReport) AbsoluteHumidity() float64 {
+	if len(r.Temperatures) == 0 {
+		return math.NaN()
+	}
+	return AbsoluteHumidity(r.Temperatures[0], r.Humidity)
+}
+
 type NamedClimateReport struct {
 	ClimateReport
 	ZoneIdentifier string
@@ -0,0 +1,79 @@
+package zeus
+
+import (
+	"fmt"
+	"math"
+)
+
+// TransitionCurve defines how values are eased from one state to
+// another during a Transition.
+type TransitionCurve int
+
+const (
+	LinearCurve TransitionCurve = iota
+	CosineCurve
+	SigmoidCurve
+	StepStartCurve
+	StepEndCurve
+)
+
+var curveNames = map[TransitionCurve]string{
+	LinearCurve:    "linear",
+	CosineCurve:    "cosine",
+	SigmoidCurve:   "sigmoid",
+	StepStartCurve: "step-start",
+	StepEndCurve:   "step-end",
+}
+
+func (c TransitionCurve) String() string {
+	if name, ok := curveNames[c]; ok == true {
+		return name
+	}
+	return fmt.Sprintf("<unknown curve %d>", int(c))
+}
+
+// ParseTransitionCurve parses a curve name. An empty name is the
+// LinearCurve.
+func ParseTransitionCurve(name string) (TransitionCurve, error) {
+	if len(name) == 0 {
+		return LinearCurve, nil
+	}
+	for c, n := range curveNames {
+		if n == name {
+			return c, nil
+		}
+	}
+	return LinearCurve, fmt.Errorf("unknown transition curve '%s'", name)
+}
+
+const sigmoidSteepness = 10.0
+
+func sigmoid(x float64) float64 {
+	return 1.0 / (1.0 + math.Exp(-sigmoidSteepness*(x-0.5)))
+}
+
+// Ease maps a linear completion in [0,1] to the eased completion in
+// [0,1]. step-start jumps to the target as soon as the transition
+// begins, step-end only when it ends.
+func (c TransitionCurve) Ease(completion float64) float64 {
+	completion = math.Min(math.Max(completion, 0.0), 1.0)
+	switch c {
+	case CosineCurve:
+		return (1.0 - math.Cos(math.Pi*completion)) / 2.0
+	case SigmoidCurve:
+		low, high := sigmoid(0), sigmoid(1)
+		return (sigmoid(completion) - low) / (high - low)
+	case StepStartCurve:
+		if completion > 0 {
+			return 1.0
+		}
+		return 0.0
+	case StepEndCurve:
+		if completion >= 1.0 {
+			return 1.0
+		}
+		return 0.0
+	default:
+		return completion
+	}
+}
@@ -0,0 +1,63 @@
+package zeus
+
+import (
+	"math"
+
+	. "gopkg.in/check.v1"
+)
+
+type CurveSuite struct{}
+
+var _ = Suite(&CurveSuite{})
+
+func (s *CurveSuite) TestParsing(c *C) {
+	testdata := []struct {
+		Name  string
+		Curve TransitionCurve
+	}{
+		{"", LinearCurve},
+		{"linear", LinearCurve},
+		{"cosine", CosineCurve},
+		{"sigmoid", SigmoidCurve},
+		{"step-start", StepStartCurve},
+		{"step-end", StepEndCurve},
+	}
+	for _, d := range testdata {
+		curve, err := ParseTransitionCurve(d.Name)
+		c.Check(err, IsNil)
+		c.Check(curve, Equals, d.Curve)
+		if len(d.Name) > 0 {
+			c.Check(curve.String(), Equals, d.Name)
+		}
+	}
+	_, err := ParseTransitionCurve("bezier")
+	c.Check(err, ErrorMatches, "unknown transition curve 'bezier'")
+}
+
+func (s *CurveSuite) TestEasing(c *C) {
+	testdata := []struct {
+		Curve      TransitionCurve
+		Completion float64
+		Expected   float64
+	}{
+		{LinearCurve, 0.25, 0.25},
+		{LinearCurve, -1.0, 0.0},
+		{LinearCurve, 2.0, 1.0},
+		{CosineCurve, 0.0, 0.0},
+		{CosineCurve, 0.5, 0.5},
+		{CosineCurve, 1.0, 1.0},
+		{SigmoidCurve, 0.0, 0.0},
+		{SigmoidCurve, 0.5, 0.5},
+		{SigmoidCurve, 1.0, 1.0},
+		{StepStartCurve, 0.0, 0.0},
+		{StepStartCurve, 0.01, 1.0},
+		{StepEndCurve, 0.99, 0.0},
+		{StepEndCurve, 1.0, 1.0},
+	}
+	for _, d := range testdata {
+		result := d.Curve.Ease(d.Completion)
+		c.Check(math.Abs(result-d.Expected) < 1e-9, Equals, true, Commentf("%s at %f: got %f, expected %f", d.Curve, d.Completion, result, d.Expected))
+	}
+	c.Check(CosineCurve.Ease(0.25) < 0.25, Equals, true)
+	c.Check(SigmoidCurve.Ease(0.25) < CosineCurve.Ease(0.25), Equals, true)
+}
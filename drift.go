@@ -0,0 +1,262 @@
+package zeus
+
+import (
+	"fmt"
+	"strings"
+	"time"
+)
+
+// DriftInterpolation defines how a DriftTable computes values
+// between two of its points.
+type DriftInterpolation int
+
+const (
+	// LinearDrift linearly interpolates between two points.
+	LinearDrift DriftInterpolation = iota
+	// StepDrift keeps the value of a point until the next one.
+	StepDrift
+)
+
+var driftInterpolationNames = map[DriftInterpolation]string{
+	LinearDrift: "linear",
+	StepDrift:   "step",
+}
+
+func (i DriftInterpolation) String() string {
+	if name, ok := driftInterpolationNames[i]; ok == true {
+		return name
+	}
+	return fmt.Sprintf("<unknown drift interpolation %d>", int(i))
+}
+
+func parseDriftInterpolation(name string) (DriftInterpolation, error) {
+	if len(name) == 0 {
+		return LinearDrift, nil
+	}
+	for i, n := range driftInterpolationNames {
+		if n == name {
+			return i, nil
+		}
+	}
+	return LinearDrift, fmt.Errorf("unknown drift interpolation '%s'", name)
+}
+
+// DriftPoint is the Value a state field should have on a given
+// experiment Day, starting at 1.
+type DriftPoint struct {
+	Day   int     `yaml:"day"`
+	Value float64 `yaml:"value"`
+}
+
+// DriftTable defines a state field as a function of the experiment
+// day. Before the first point and after the last one, the value is
+// constant.
+type DriftTable struct {
+	Interpolation DriftInterpolation
+	Points        []DriftPoint
+}
+
+func (t *DriftTable) Check() error {
+	if len(t.Points) == 0 {
+		return fmt.Errorf("drift table needs at least one point")
+	}
+	for i, p := range t.Points {
+		if p.Day < 1 {
+			return fmt.Errorf("invalid drift day %d: days start at 1", p.Day)
+		}
+		if i > 0 && p.Day <= t.Points[i-1].Day {
+			return fmt.Errorf("drift days should be strictly increasing (day %d after day %d)", p.Day, t.Points[i-1].Day)
+		}
+	}
+	return nil
+}
+
+// Value returns the value of the table for a fractional experiment
+// day.
+func (t *DriftTable) Value(day float64) float64 {
+	first := t.Points[0]
+	if day <= float64(first.Day) {
+		return first.Value
+	}
+	for i, p := range t.Points[1:] {
+		if day >= float64(p.Day) {
+			continue
+		}
+		prev := t.Points[i]
+		if t.Interpolation == StepDrift {
+			return prev.Value
+		}
+		completion := (day - float64(prev.Day)) / float64(p.Day-prev.Day)
+		return interpolate(prev.Value, p.Value, completion)
+	}
+	return t.Points[len(t.Points)-1].Value
+}
+
+func (t *DriftTable) String() string {
+	points := make([]string, 0, len(t.Points))
+	for _, p := range t.Points {
+		points = append(points, fmt.Sprintf("%d:%g", p.Day, p.Value))
+	}
+	return fmt.Sprintf("%s[%s]", t.Interpolation, strings.Join(points, " "))
+}
+
+type driftTableShadow struct {
+	Interpolation string       `yaml:"interpolation,omitempty"`
+	Table         []DriftPoint `yaml:"table"`
+}
+
+func (t *DriftTable) UnmarshalYAML(unmarshal func(interface{}) error) error {
+	shadow := driftTableShadow{}
+	if err := unmarshal(&shadow); err != nil {
+		return err
+	}
+	var err error
+	t.Interpolation, err = parseDriftInterpolation(shadow.Interpolation)
+	if err != nil {
+		return err
+	}
+	t.Points = shadow.Table
+	return t.Check()
+}
+
+func (t DriftTable) MarshalYAML() (interface{}, error) {
+	res := driftTableShadow{Table: t.Points}
+	if t.Interpolation != LinearDrift {
+		res.Interpolation = t.Interpolation.String()
+	}
+	return res, nil
+}
+
+// driftValue is a state field in a season file: either a constant
+// value or a DriftTable.
+type driftValue struct {
+	Value float64
+	Table *DriftTable
+}
+
+func (v *driftValue) UnmarshalYAML(unmarshal func(interface{}) error) error {
+	if err := unmarshal(&v.Value); err == nil {
+		v.Table = nil
+		return nil
+	}
+	table := &DriftTable{}
+	if err := unmarshal(table); err != nil {
+		return err
+	}
+	v.Table = table
+	v.Value = table.Points[0].Value
+	return nil
+}
+
+// StateDrift holds the fields of a State that change with the
+// experiment day. A nil table means the field is constant.
+type StateDrift struct {
+	Temperature  *DriftTable
+	Humidity     *DriftTable
+	Wind         *DriftTable
+	VisibleLight *DriftTable
+	UVLight      *DriftTable
+	// Channels holds the tables of registered channels other than
+	// the built-in ones.
+	Channels map[string]*DriftTable
+
+	// origin is the beginning of the first experiment day, and
+	// weather the random jitter to apply. Both are set by the
+	// ClimateInterpoler.
+	origin  time.Time
+	weather *Weather
+}
+
+func (d *StateDrift) String() string {
+	res := make([]string, 0, len(channels))
+	for _, c := range channels {
+		if table := c.Drift(d); table != nil {
+			res = append(res, c.Name+": "+table.String())
+		}
+	}
+	if d.weather != nil {
+		res = append(res, fmt.Sprintf("jitter: ±%g°C ±%g%% R.H.", d.weather.Jitter.Temperature, d.weather.Jitter.Humidity))
+	}
+	return "{" + strings.Join(res, ", ") + "}"
+}
+
+// empty returns true if d does not define any table.
+func (d *StateDrift) empty() bool {
+	for _, c := range channels {
+		if c.Drift(d) != nil {
+			return false
+		}
+	}
+	return true
+}
+
+// bind returns a copy of d starting its first day at origin, and
+// jittered by weather.
+func (d *StateDrift) bind(origin time.Time, weather *Weather) *StateDrift {
+	if d == nil && weather.hasJitter() == false {
+		return nil
+	}
+	res := StateDrift{}
+	if d != nil {
+		res = *d
+	}
+	res.origin = origin
+	if weather.hasJitter() == true {
+		res.weather = weather
+	}
+	return &res
+}
+
+// inherit completes the drift of a state, whose undefined fields are
+// taken from other, with the tables of other.
+func (d *StateDrift) inherit(s, other State) *StateDrift {
+	if other.Drift == nil {
+		return d
+	}
+	res := StateDrift{}
+	if d != nil {
+		res = *d
+	}
+	for _, c := range channels {
+		if IsUndefined(c.Value(s)) && c.Drift(&res) == nil {
+			c.setTable(&res, c.Drift(other.Drift))
+		}
+	}
+	if res.empty() == true {
+		return nil
+	}
+	return &res
+}
+
+// day returns the fractional experiment day of t, starting at 1.
+func (d *StateDrift) day(t time.Time) float64 {
+	return 1.0 + t.Sub(d.origin).Hours()/24.0
+}
+
+// At returns the State with all drifting fields evaluated at t, and
+// jittered by the zone weather. Values are not clamped: the zone
+// actuators clamp them to their hardware limits.
+func (s State) At(t time.Time) State {
+	if s.Drift == nil {
+		return s
+	}
+	day := s.Drift.day(t)
+	res := s
+	res.Drift = nil
+	for _, c := range channels {
+		if table := c.Drift(s.Drift); table != nil {
+			c.set(&res, table.Value(day))
+		}
+	}
+	if w := s.Drift.weather; w != nil {
+		if IsUndefined(res.Temperature) == false {
+			offset := w.jitter(day, w.Jitter.Temperature, weatherSalt("temperature"))
+			res.Temperature += Temperature(offset)
+		}
+		if IsUndefined(res.Humidity) == false {
+			offset := w.jitter(day, w.Jitter.Humidity, weatherSalt("humidity"))
+			res.Humidity += Humidity(offset)
+		}
+	}
+	return res
+}
@@ -0,0 +1,139 @@
+package zeus
+
+import (
+	"time"
+
+	. "gopkg.in/check.v1"
+	yaml "gopkg.in/yaml.v2"
+)
+
+type DriftSuite struct{}
+
+var _ = Suite(&DriftSuite{})
+
+func (s *DriftSuite) TestTableValue(c *C) {
+	table := DriftTable{
+		Points: []DriftPoint{{Day: 30, Value: 26}, {Day: 60, Value: 29}, {Day: 70, Value: 25}},
+	}
+	testdata := []struct {
+		Day             float64
+		Linear, Stepped float64
+	}{
+		{1, 26, 26},
+		{30, 26, 26},
+		{45, 27.5, 26},
+		{59.5, 28.95, 26},
+		{60, 29, 29},
+		{65, 27, 29},
+		{70, 25, 25},
+		{200, 25, 25},
+	}
+	for _, d := range testdata {
+		table.Interpolation = LinearDrift
+		c.Check(table.Value(d.Day), Equals, d.Linear, Commentf("day %g", d.Day))
+		table.Interpolation = StepDrift
+		c.Check(table.Value(d.Day), Equals, d.Stepped, Commentf("day %g", d.Day))
+	}
+}
+
+func (s *DriftSuite) TestParsing(c *C) {
+	state := State{}
+	err := yaml.Unmarshal([]byte(`name: day
+temperature:
+  table:
+    - {day: 30, value: 26}
+    - {day: 60, value: 29}
+humidity: 60
+wind:
+  interpolation: step
+  table:
+    - {day: 1, value: 100}
+    - {day: 10, value: 50}
+`), &state)
+	c.Assert(err, IsNil)
+	c.Check(state.Temperature, Equals, Temperature(26))
+	c.Check(state.Humidity, Equals, Humidity(60))
+	c.Check(state.Wind, Equals, Wind(100))
+	c.Assert(state.Drift, Not(IsNil))
+	c.Check(state.Drift.Humidity, IsNil)
+	c.Check(*state.Drift.Temperature, DeepEquals, DriftTable{
+		Interpolation: LinearDrift,
+		Points:        []DriftPoint{{30, 26}, {60, 29}},
+	})
+	c.Check(*state.Drift.Wind, DeepEquals, DriftTable{
+		Interpolation: StepDrift,
+		Points:        []DriftPoint{{1, 100}, {10, 50}},
+	})
+
+	out, err := yaml.Marshal(state)
+	c.Assert(err, IsNil)
+	c.Check(string(out), Equals, `name: day
+humidity: 60
+temperature:
+  table:
+  - day: 30
+    value: 26
+  - day: 60
+    value: 29
+wind:
+  interpolation: step
+  table:
+  - day: 1
+    value: 100
+  - day: 10
+    value: 50
+`)
+
+	errors := []struct {
+		Text, Error string
+	}{
+		{"temperature: {table: []}", "drift table needs at least one point"},
+		{"temperature: {table: [{day: 0, value: 3}]}", "invalid drift day 0: days start at 1"},
+		{"temperature: {table: [{day: 4, value: 3}, {day: 4, value: 5}]}", `drift days should be strictly increasing \(day 4 after day 4\)`},
+		{"temperature: {interpolation: cubic, table: [{day: 4, value: 3}]}", "unknown drift interpolation 'cubic'"},
+	}
+	for _, d := range errors {
+		c.Check(yaml.Unmarshal([]byte(d.Text), &state), ErrorMatches, d.Error)
+	}
+}
+
+func (s *DriftSuite) TestInterpolation(c *C) {
+	at := func(h int) time.Time { return time.Date(0, 1, 1, h, 0, 0, 0, time.UTC) }
+	states := []State{
+		{
+			Name:        "day",
+			Temperature: 26,
+			Humidity:    60,
+			Drift: &StateDrift{
+				Temperature: &DriftTable{Points: []DriftPoint{{30, 26}, {60, 29}}},
+				Humidity:    &DriftTable{Points: []DriftPoint{{1, 60}, {11, 160}}},
+			},
+		},
+		{Name: "night", Temperature: 22, Humidity: 60},
+	}
+	transitions := []Transition{
+		{From: "night", To: "day", Start: at(6), Duration: 2 * time.Hour},
+		{From: "day", To: "night", Start: at(18)},
+	}
+	reference := time.Date(2021, 3, 1, 0, 0, 0, 0, time.UTC)
+	i, err := NewClimateInterpoler(states, transitions, reference)
+	c.Assert(err, IsNil)
+
+	// day 45, at noon
+	t := reference.AddDate(0, 0, 44).Add(12 * time.Hour)
+	current, _, _ := i.CurrentInterpolation(t)
+	res := current.State(t)
+	c.Check(res.Temperature, Equals, Temperature(27.55))
+	// not clamped to the unit bounds, the actuators clamp it to the
+	// zone hardware limits
+	c.Check(res.Humidity, Equals, Humidity(160))
+	c.Check(res.Drift, IsNil)
+
+	// day 3, half of the morning transition
+	t = reference.AddDate(0, 0, 2).Add(7 * time.Hour)
+	current, _, _ = i.CurrentInterpolation(t)
+	res = current.State(t)
+	c.Check(res.Temperature, Equals, Temperature(24))
+	c.Check(res.Humidity, Equals, Humidity(60+(60+10*(2+7.0/24)-60)/2))
+	c.Check(current.End().Temperature, Equals, Temperature(26))
+}
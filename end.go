@@ -0,0 +1,145 @@
+package zeus
+
+import (
+	"fmt"
+	"time"
+)
+
+// ExperimentEnd schedules the end of the experiment of a zone, either
+// on a Day (starting at 1) at a given Time, or at an absolute
+// Date. The zone then moves to the safe State, or stops if State is
+// empty.
+type ExperimentEnd struct {
+	Day  int
+	Time time.Time
+	// Date is either a RFC3339 time, or a "2006-01-02 15:04" time
+	// in the zone timezone.
+	Date  string
+	State string
+}
+
+type experimentEndShadow struct {
+	Day   int    `yaml:"day,omitempty"`
+	Time  string `yaml:"time,omitempty"`
+	Date  string `yaml:"date,omitempty"`
+	State string `yaml:"state,omitempty"`
+}
+
+func (e *ExperimentEnd) Check() error {
+	if e.Day < 0 {
+		return fmt.Errorf("invalid experiment end day %d: days start at 1", e.Day)
+	}
+	if (e.Day > 0) == (len(e.Date) > 0) {
+		return fmt.Errorf("experiment end requires either a day or a date")
+	}
+	if len(e.Date) > 0 {
+		if _, err := e.parseDate(time.UTC); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (e *ExperimentEnd) parseDate(location *time.Location) (time.Time, error) {
+	if t, err := time.Parse(time.RFC3339, e.Date); err == nil {
+		return t, nil
+	}
+	t, err := time.Parse("2006-01-02 15:04", e.Date)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("invalid experiment end date '%s': should be like '2006-01-02 15:04' or RFC3339", e.Date)
+	}
+	y, m, d := t.Date()
+	return wallClock(y, m, d, t.Hour(), t.Minute(), location), nil
+}
+
+// Resolve returns the instant the experiment ends, for an experiment
+// started on the reference date in location.
+func (e *ExperimentEnd) Resolve(reference time.Time, location *time.Location) (time.Time, error) {
+	if len(e.Date) > 0 {
+		return e.parseDate(location)
+	}
+	y, m, d := reference.In(location).Date()
+	date := time.Date(y, m, d+e.Day-1, 0, 0, 0, 0, time.UTC)
+	return wallClock(date.Year(), date.Month(), date.Day(), e.Time.Hour(), e.Time.Minute(), location), nil
+}
+
+func (e *ExperimentEnd) UnmarshalYAML(unmarshal func(interface{}) error) error {
+	shadow := experimentEndShadow{}
+	if err := unmarshal(&shadow); err != nil {
+		return err
+	}
+	e.Day = shadow.Day
+	e.Date = shadow.Date
+	e.State = shadow.State
+	e.Time = time.Date(0, 1, 1, 0, 0, 0, 0, time.UTC)
+	if len(shadow.Time) > 0 {
+		if e.Day == 0 {
+			return fmt.Errorf("experiment end time requires a day")
+		}
+		var err error
+		e.Time, err = time.Parse("15:04", shadow.Time)
+		if err != nil {
+			return err
+		}
+	}
+	return e.Check()
+}
+
+func (e ExperimentEnd) MarshalYAML() (interface{}, error) {
+	res := experimentEndShadow{
+		Day:   e.Day,
+		Date:  e.Date,
+		State: e.State,
+	}
+	if e.Day > 0 {
+		res.Time = e.Time.Format("15:04")
+	}
+	return res, nil
+}
+
+func (e ExperimentEnd) String() string {
+	res := e.Date
+	if e.Day > 0 {
+		res = fmt.Sprintf("day %d at %s", e.Day, e.Time.Format("15:04"))
+	}
+	if len(e.State) == 0 {
+		return res + ", then stop"
+	}
+	return res + ", then '" + e.State + "'"
+}
+
+// StoppedStateName is the name of the State of a zone whose
+// experiment ended without a safe state.
+const StoppedStateName = "stopped"
+
+// stoppedClimate is the Interpolation of a zone whose experiment
+// ended without a safe state.
+type stoppedClimate struct {
+	since time.Time
+}
+
+func (s *stoppedClimate) State(time.Time) State {
+	return State{
+		Name:         StoppedStateName,
+		Temperature:  UndefinedTemperature,
+		Humidity:     UndefinedHumidity,
+		Wind:         UndefinedWind,
+		VisibleLight: UndefinedLight,
+		UVLight:      UndefinedLight,
+	}
+}
+
+func (s *stoppedClimate) String() string {
+	return fmt.Sprintf("experiment stopped at %s", s.since)
+}
+
+func (s *stoppedClimate) End() *State {
+	return nil
+}
+
+// IsStopped returns true if i is the Interpolation of a zone whose
+// experiment ended, and which should stop all climate control.
+func IsStopped(i Interpolation) bool {
+	_, ok := i.(*stoppedClimate)
+	return ok
+}
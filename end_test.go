@@ -0,0 +1,147 @@
+package zeus
+
+import (
+	"time"
+
+	. "gopkg.in/check.v1"
+	yaml "gopkg.in/yaml.v2"
+)
+
+type ExperimentEndSuite struct{}
+
+var _ = Suite(&ExperimentEndSuite{})
+
+func (s *ExperimentEndSuite) TestParsing(c *C) {
+	testdata := []struct {
+		Text string
+		End  ExperimentEnd
+	}{
+		{
+			Text: `day: 30
+time: "12:00"
+state: safe
+`,
+			End: ExperimentEnd{Day: 30, Time: time.Date(0, 1, 1, 12, 0, 0, 0, time.UTC), State: "safe"},
+		},
+		{
+			Text: `date: 2021-06-01 12:00
+`,
+			End: ExperimentEnd{Time: time.Date(0, 1, 1, 0, 0, 0, 0, time.UTC), Date: "2021-06-01 12:00"},
+		},
+	}
+	for _, d := range testdata {
+		end := ExperimentEnd{}
+		c.Check(yaml.Unmarshal([]byte(d.Text), &end), IsNil)
+		c.Check(end, DeepEquals, d.End)
+		out, err := yaml.Marshal(end)
+		c.Check(err, IsNil)
+		c.Check(string(out), Equals, d.Text)
+	}
+
+	errors := []struct {
+		Text, Error string
+	}{
+		{"state: safe", "experiment end requires either a day or a date"},
+		{"{day: 3, date: 2021-06-01 12:00}", "experiment end requires either a day or a date"},
+		{"{date: 2021-06-01 12:00, time: 12:00}", "experiment end time requires a day"},
+		{"{date: 2021-06-01}", "invalid experiment end date '2021-06-01': .*"},
+		{"{day: -3}", "invalid experiment end day -3: days start at 1"},
+	}
+	for _, d := range errors {
+		end := ExperimentEnd{}
+		c.Check(yaml.Unmarshal([]byte(d.Text), &end), ErrorMatches, d.Error)
+	}
+}
+
+func (s *ExperimentEndSuite) TestResolve(c *C) {
+	paris, err := time.LoadLocation("Europe/Paris")
+	c.Assert(err, IsNil)
+	reference := time.Date(2021, 3, 1, 9, 0, 0, 0, paris)
+	testdata := []struct {
+		End      ExperimentEnd
+		Location *time.Location
+		Expected time.Time
+	}{
+		{
+			End:      ExperimentEnd{Day: 1, Time: time.Date(0, 1, 1, 12, 0, 0, 0, time.UTC)},
+			Location: time.UTC,
+			Expected: time.Date(2021, 3, 1, 12, 0, 0, 0, time.UTC),
+		},
+		{
+			End:      ExperimentEnd{Day: 30, Time: time.Date(0, 1, 1, 12, 0, 0, 0, time.UTC)},
+			Location: paris,
+			Expected: time.Date(2021, 3, 30, 10, 0, 0, 0, time.UTC),
+		},
+		{
+			End:      ExperimentEnd{Date: "2021-06-01 12:00"},
+			Location: paris,
+			Expected: time.Date(2021, 6, 1, 10, 0, 0, 0, time.UTC),
+		},
+		{
+			End:      ExperimentEnd{Date: "2021-06-01T12:00:00Z"},
+			Location: paris,
+			Expected: time.Date(2021, 6, 1, 12, 0, 0, 0, time.UTC),
+		},
+	}
+	for _, d := range testdata {
+		end, err := d.End.Resolve(reference, d.Location)
+		c.Check(err, IsNil)
+		c.Check(end.Equal(d.Expected), Equals, true, Commentf("expected %s, got %s", d.Expected, end))
+	}
+}
+
+func (s *ExperimentEndSuite) TestInterpolation(c *C) {
+	at := func(h int) time.Time { return time.Date(0, 1, 1, h, 0, 0, 0, time.UTC) }
+	climate := ZoneClimate{
+		States: []State{
+			{Name: "day", Temperature: 26},
+			{Name: "night", Temperature: 22},
+			{Name: "safe", Temperature: 20},
+		},
+		Transitions: []Transition{
+			{From: "night", To: "day", Start: at(6), Duration: time.Hour},
+			{From: "day", To: "night", Start: at(18), Duration: time.Hour},
+		},
+		End: &ExperimentEnd{Day: 3, Time: at(12), State: "safe"},
+	}
+	reference := time.Date(2021, 3, 1, 0, 0, 0, 0, time.UTC)
+	end := time.Date(2021, 3, 3, 12, 0, 0, 0, time.UTC)
+
+	i, err := NewZoneClimateInterpoler(climate, reference)
+	c.Assert(err, IsNil)
+	endTime, ok := i.ExperimentEnd()
+	c.Check(ok, Equals, true)
+	c.Check(endTime, Equals, end)
+
+	current, nextTime, next := i.CurrentInterpolation(end.Add(-time.Hour))
+	c.Check(current.State(end).Name, Equals, "day")
+	c.Check(nextTime, Equals, end)
+	c.Check(next.State(end).Name, Equals, "safe")
+
+	// a transition is interrupted by the end
+	climate.End.Time = at(18).Add(30 * time.Minute)
+	i, err = NewZoneClimateInterpoler(climate, reference)
+	c.Assert(err, IsNil)
+	current, nextTime, next = i.CurrentInterpolation(time.Date(2021, 3, 3, 18, 10, 0, 0, time.UTC))
+	c.Check(current.End(), Not(IsNil))
+	c.Check(nextTime, Equals, time.Date(2021, 3, 3, 18, 30, 0, 0, time.UTC))
+	c.Check(next.State(nextTime).Name, Equals, "safe")
+
+	current, _, next = i.CurrentInterpolation(time.Date(2021, 3, 10, 0, 0, 0, 0, time.UTC))
+	c.Check(current.State(end).Name, Equals, "safe")
+	c.Check(current.State(end).Temperature, Equals, Temperature(20))
+	c.Check(IsStopped(current), Equals, false)
+	c.Check(next, IsNil)
+
+	climate.End.State = ""
+	i, err = NewZoneClimateInterpoler(climate, reference)
+	c.Assert(err, IsNil)
+	current, _, next = i.CurrentInterpolation(time.Date(2021, 3, 10, 0, 0, 0, 0, time.UTC))
+	c.Check(IsStopped(current), Equals, true)
+	c.Check(current.State(end).Name, Equals, StoppedStateName)
+	c.Check(next, IsNil)
+
+	climate.End.State = "foo"
+	_, err = NewZoneClimateInterpoler(climate, reference)
+	c.Check(err, ErrorMatches, "Undefined state 'foo' in experiment end")
+}
@@ -0,0 +1,196 @@
+package zeus
+
+import (
+	"fmt"
+	"math"
+	"sort"
+	"strings"
+	"time"
+)
+
+// A zone can declare `follow: <zone>` instead of its own states and
+// transitions, to run the schedule of another zone of the season,
+// delayed by `time-offset` and with the values of some channels
+// shifted by `offsets`. Followers are resolved with
+// SeasonFile.ResolveZones.
+
+func (c ZoneClimate) follows() bool {
+	return len(c.Follow) > 0
+}
+
+// ResolveZones returns the climate of all zones of the season, where
+// the zones following another one are replaced by the schedule they
+// follow. It returns an error if a followed zone is undefined or if
+// zones follow each other in a cycle.
+func (f SeasonFile) ResolveZones() (map[string]ZoneClimate, error) {
+	names := make([]string, 0, len(f.Zones))
+	for name := range f.Zones {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	res := make(map[string]ZoneClimate, len(f.Zones))
+	for _, name := range names {
+		climate, err := f.resolveZone(name, nil)
+		if err != nil {
+			return nil, err
+		}
+		res[name] = climate
+	}
+	return res, nil
+}
+
+func (f SeasonFile) resolveZone(name string, visited []string) (ZoneClimate, error) {
+	for i, v := range visited {
+		if v == name {
+			return ZoneClimate{}, fmt.Errorf("zones follow each other in a cycle: %s -> %s",
+				strings.Join(visited[i:], " -> "), name)
+		}
+	}
+	climate := f.Zones[name]
+	if climate.follows() == false {
+		if climate.TimeOffset != 0 || len(climate.Offsets) > 0 {
+			return ZoneClimate{}, fmt.Errorf("zone '%s' defines offsets but does not follow any zone", name)
+		}
+		return climate, nil
+	}
+	if len(climate.States) > 0 || len(climate.Transitions) > 0 {
+		return ZoneClimate{}, fmt.Errorf("zone '%s' follows '%s' and cannot define states or transitions", name, climate.Follow)
+	}
+	// the schedule settings are the ones of the followed zone
+	for _, field := range []struct {
+		name string
+		set  bool
+	}{
+		{"timezone", len(climate.Timezone) > 0},
+		{"latitude", climate.Latitude != nil},
+		{"longitude", climate.Longitude != nil},
+		{"weather", climate.Weather != nil},
+		{"end", climate.End != nil},
+	} {
+		if field.set == true {
+			return ZoneClimate{}, fmt.Errorf("zone '%s' follows '%s' and cannot define its %s", name, climate.Follow, field.name)
+		}
+	}
+	if _, ok := f.Zones[climate.Follow]; ok == false {
+		return ZoneClimate{}, fmt.Errorf("zone '%s' follows undefined zone '%s'", name, climate.Follow)
+	}
+	followed, err := f.resolveZone(climate.Follow, append(visited, name))
+	if err != nil {
+		return ZoneClimate{}, err
+	}
+	states, err := offsetStates(followed.States, climate.Offsets)
+	if err != nil {
+		return ZoneClimate{}, fmt.Errorf("zone '%s': %s", name, err)
+	}
+	// the alarm settings are the ones of the follower
+	res := followed
+	res.MinimalTemperature = climate.MinimalTemperature
+	res.MaximalTemperature = climate.MaximalTemperature
+	res.MinimalHumidity = climate.MinimalHumidity
+	res.MaximalHumidity = climate.MaximalHumidity
+	res.AlarmDelay = climate.AlarmDelay
+	res.Hysteresis = climate.Hysteresis
+	res.MaximalTemperatureRate = climate.MaximalTemperatureRate
+	res.MaximalHumidityRate = climate.MaximalHumidityRate
+	res.RateWindow = climate.RateWindow
+	res.Follow = ""
+	res.Offsets = nil
+	res.States = states
+	res.TimeOffset = followed.TimeOffset + climate.TimeOffset
+	return res, nil
+}
+
+func offsetTable(t *DriftTable, offset float64) *DriftTable {
+	if t == nil {
+		return nil
+	}
+	res := &DriftTable{Interpolation: t.Interpolation, Points: make([]DriftPoint, 0, len(t.Points))}
+	for _, p := range t.Points {
+		res.Points = append(res.Points, DriftPoint{Day: p.Day, Value: p.Value + offset})
+	}
+	return res
+}
+
+// offsetStates returns a copy of states, where the defined values of
+// the channels in offsets are shifted.
+func offsetStates(states []State, offsets map[string]float64) ([]State, error) {
+	names := make([]string, 0, len(offsets))
+	for name := range offsets {
+		if _, ok := LookupChannel(name); ok == false {
+			return nil, fmt.Errorf("unknown channel '%s' in offsets", name)
+		}
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	res := make([]State, 0, len(states))
+	for _, s := range states {
+		if s.Drift != nil {
+			drift := *s.Drift
+			s.Drift = &drift
+		}
+		for _, name := range names {
+			c, _ := LookupChannel(name)
+			if c == HumidityChannel && s.HumidityTarget != nil {
+				return nil, fmt.Errorf("cannot offset the humidity of state '%s', which defines a vpd or absolute-humidity", s.Name)
+			}
+			if v := c.get(s); math.IsInf(v, -1) == false {
+				c.set(&s, v+offsets[name])
+			}
+			if table := c.Drift(s.Drift); table != nil {
+				c.setTable(s.Drift, offsetTable(table, offsets[name]))
+			}
+		}
+		res = append(res, s)
+	}
+	return res, nil
+}
+
+// shiftedInterpoler delays the schedule of a ClimateInterpoler.
+type shiftedInterpoler struct {
+	interpoler ClimateInterpoler
+	offset     time.Duration
+}
+
+func (i *shiftedInterpoler) shift(interpolation Interpolation) Interpolation {
+	if interpolation == nil {
+		return nil
+	}
+	if stopped, ok := interpolation.(*stoppedClimate); ok == true {
+		return &stoppedClimate{since: stopped.since.Add(i.offset)}
+	}
+	return &shiftedInterpolation{interpolation: interpolation, offset: i.offset}
+}
+
+func (i *shiftedInterpoler) CurrentInterpolation(t time.Time) (Interpolation, time.Time, Interpolation) {
+	current, next, nextInterpolation := i.interpoler.CurrentInterpolation(t.Add(-i.offset))
+	if next.IsZero() == false {
+		next = next.Add(i.offset)
+	}
+	return i.shift(current), next, i.shift(nextInterpolation)
+}
+
+func (i *shiftedInterpoler) ExperimentEnd() (time.Time, bool) {
+	end, ok := i.interpoler.ExperimentEnd()
+	if ok == false {
+		return end, false
+	}
+	return end.Add(i.offset), true
+}
+
+type shiftedInterpolation struct {
+	interpolation Interpolation
+	offset        time.Duration
+}
+
+func (i *shiftedInterpolation) State(t time.Time) State {
+	return i.interpolation.State(t.Add(-i.offset))
+}
+
+func (i *shiftedInterpolation) String() string {
+	return fmt.Sprintf("%s delayed by %s", i.interpolation, i.offset)
+}
+
+func (i *shiftedInterpolation) End() *State {
+	return i.interpolation.End()
+}
@@ -0,0 +1,159 @@
+package zeus
+
+import (
+	"math"
+	"time"
+
+	. "gopkg.in/check.v1"
+	yaml "gopkg.in/yaml.v2"
+)
+
+type FollowSuite struct{}
+
+var _ = Suite(&FollowSuite{})
+
+func (s *FollowSuite) TestParsing(c *C) {
+	season := SeasonFile{}
+	c.Assert(yaml.Unmarshal([]byte(`
+zones:
+  control:
+    maximal-temperature: 30
+    follow: treatment
+    time-offset: 3h
+    offsets:
+      temperature: -2
+`), &season), IsNil)
+	c.Check(season.Zones["control"].Follow, Equals, "treatment")
+	c.Check(season.Zones["control"].TimeOffset, Equals, 3*time.Hour)
+	c.Check(season.Zones["control"].Offsets, DeepEquals, map[string]float64{"temperature": -2})
+}
+
+func (s *FollowSuite) TestResolution(c *C) {
+	at := func(h int) time.Time { return time.Date(0, 1, 1, h, 0, 0, 0, time.UTC) }
+	treatment := ZoneClimate{
+		MaximalTemperature: 30,
+		AlarmDelay:         10 * time.Minute,
+		States: []State{
+			{Name: "day", Temperature: 26, Humidity: 60, Wind: UndefinedWind, VisibleLight: 100, UVLight: UndefinedLight},
+			{
+				Name: "night", Temperature: 20, Humidity: UndefinedHumidity, Wind: UndefinedWind, VisibleLight: 0, UVLight: UndefinedLight,
+				Drift: &StateDrift{Temperature: &DriftTable{Points: []DriftPoint{{1, 20}, {11, 22}}}},
+			},
+		},
+		Transitions: []Transition{
+			{From: "night", To: "day", Start: at(6), Duration: time.Hour},
+			{From: "day", To: "night", Start: at(18), Duration: time.Hour},
+		},
+	}
+	season := SeasonFile{
+		Zones: map[string]ZoneClimate{
+			"treatment": treatment,
+			"control": {
+				MaximalTemperature: 28,
+				AlarmDelay:         time.Minute,
+				Follow:             "treatment",
+				TimeOffset:         2 * time.Hour,
+				Offsets:            map[string]float64{"temperature": -2, "wind": 50},
+			},
+			"shifted": {
+				Follow:     "control",
+				TimeOffset: time.Hour,
+				Offsets:    map[string]float64{"temperature": 1},
+			},
+		},
+	}
+	zones, err := season.ResolveZones()
+	c.Assert(err, IsNil)
+	c.Check(zones["treatment"], DeepEquals, treatment)
+
+	control := zones["control"]
+	c.Check(control.Follow, Equals, "")
+	c.Check(control.TimeOffset, Equals, 2*time.Hour)
+	c.Check(control.MaximalTemperature, Equals, Temperature(28))
+	c.Check(control.AlarmDelay, Equals, time.Minute)
+	c.Check(control.Transitions, DeepEquals, treatment.Transitions)
+	c.Check(control.States[0].Temperature, Equals, Temperature(24))
+	c.Check(IsUndefined(control.States[0].Wind), Equals, true)
+	c.Check(control.States[1].Drift.Temperature.Points, DeepEquals, []DriftPoint{{1, 18}, {11, 20}})
+	// the followed zone is not modified
+	c.Check(treatment.States[1].Drift.Temperature.Points, DeepEquals, []DriftPoint{{1, 20}, {11, 22}})
+
+	shifted := zones["shifted"]
+	c.Check(shifted.TimeOffset, Equals, 3*time.Hour)
+	c.Check(shifted.States[0].Temperature, Equals, Temperature(25))
+
+	// the follower reaches the day 2 hours after the followed zone
+	reference := time.Date(2021, 3, 1, 0, 0, 0, 0, time.UTC)
+	i, err := NewZoneClimateInterpoler(control, reference)
+	c.Assert(err, IsNil)
+	current, next, nextInterpolation := i.CurrentInterpolation(reference.Add(7 * time.Hour))
+	c.Check(current.State(reference.Add(7*time.Hour)).Name, Equals, "night")
+	c.Check(next, Equals, reference.Add(8*time.Hour))
+	// halfway from night, which drifts from 18, to day at 24
+	middle := nextInterpolation.State(reference.Add(8*time.Hour + 30*time.Minute)).Temperature
+	c.Check(math.Abs(middle.Value()-21) < 0.1, Equals, true, Commentf("obtained %g", middle))
+	description, ok := DescribeTransition(nextInterpolation)
+	c.Check(ok, Equals, true)
+	c.Check(description.Start, Equals, reference.Add(8*time.Hour))
+
+	_, err = NewZoneClimateInterpoler(season.Zones["control"], reference)
+	c.Check(err, ErrorMatches, "zone follows 'treatment': it should be resolved with SeasonFile.ResolveZones")
+}
+
+func (s *FollowSuite) TestErrors(c *C) {
+	latitude := 46.52
+	state := State{Name: "day", Temperature: 26, HumidityTarget: &HumidityTarget{VaporPressureDeficit: 1.2}}
+	testdata := []struct {
+		Zones map[string]ZoneClimate
+		Error string
+	}{
+		{
+			map[string]ZoneClimate{"a": {Follow: "b"}},
+			"zone 'a' follows undefined zone 'b'",
+		},
+		{
+			map[string]ZoneClimate{"a": {Follow: "b"}, "b": {Follow: "c"}, "c": {Follow: "a"}},
+			"zones follow each other in a cycle: a -> b -> c -> a",
+		},
+		{
+			map[string]ZoneClimate{"a": {Follow: "a"}},
+			"zones follow each other in a cycle: a -> a",
+		},
+		{
+			map[string]ZoneClimate{"a": {Follow: "b", States: []State{state}}, "b": {States: []State{state}}},
+			"zone 'a' follows 'b' and cannot define states or transitions",
+		},
+		{
+			map[string]ZoneClimate{"a": {Follow: "b", Timezone: "Europe/Paris"}, "b": {States: []State{state}}},
+			"zone 'a' follows 'b' and cannot define its timezone",
+		},
+		{
+			map[string]ZoneClimate{"a": {Follow: "b", Latitude: &latitude}, "b": {States: []State{state}}},
+			"zone 'a' follows 'b' and cannot define its latitude",
+		},
+		{
+			map[string]ZoneClimate{"a": {Follow: "b", Weather: &Weather{}}, "b": {States: []State{state}}},
+			"zone 'a' follows 'b' and cannot define its weather",
+		},
+		{
+			map[string]ZoneClimate{"a": {Follow: "b", End: &ExperimentEnd{}}, "b": {States: []State{state}}},
+			"zone 'a' follows 'b' and cannot define its end",
+		},
+		{
+			map[string]ZoneClimate{"a": {TimeOffset: time.Hour, States: []State{state}}},
+			"zone 'a' defines offsets but does not follow any zone",
+		},
+		{
+			map[string]ZoneClimate{"a": {Follow: "b", Offsets: map[string]float64{"temprature": 1}}, "b": {States: []State{state}}},
+			"zone 'a': unknown channel 'temprature' in offsets",
+		},
+		{
+			map[string]ZoneClimate{"a": {Follow: "b", Offsets: map[string]float64{"humidity": 1}}, "b": {States: []State{state}}},
+			"zone 'a': cannot offset the humidity of state 'day', which defines a vpd or absolute-humidity",
+		},
+	}
+	for _, d := range testdata {
+		_, err := SeasonFile{Zones: d.Zones}.ResolveZones()
+		c.Check(err, ErrorMatches, d.Error)
+	}
+}
@@ -0,0 +1,104 @@
+package zeus
+
+import (
+	"fmt"
+	"math"
+)
+
+// SaturationVaporPressure returns the saturation vapour pressure of
+// water in kPa at temperature t, using the Magnus formula.
+func SaturationVaporPressure(t Temperature) float64 {
+	return 0.61094 * math.Exp(17.625*t.Value()/(t.Value()+243.04))
+}
+
+// VaporPressureDeficit returns the vapour-pressure deficit in kPa of
+// air at temperature t and relative humidity h.
+func VaporPressureDeficit(t Temperature, h Humidity) float64 {
+	return SaturationVaporPressure(t) * (1.0 - h.Value()/100.0)
+}
+
+// absoluteHumidityFactor converts a vapour pressure in kPa over a
+// temperature in K to an absolute humidity in g/m³.
+const absoluteHumidityFactor = 2166.79
+
+// AbsoluteHumidity returns the mass of water vapour in g/m³ of air at
+// temperature t and relative humidity h.
+func AbsoluteHumidity(t Temperature, h Humidity) float64 {
+	return absoluteHumidityFactor * SaturationVaporPressure(t) * h.Value() / 100.0 / (t.Value() + 273.15)
+}
+
+// HumidityTarget defines the humidity of a State independently of its
+// temperature, either as a vapour-pressure deficit or as an absolute
+// humidity. Exactly one of the field is non-zero.
+type HumidityTarget struct {
+	// VaporPressureDeficit in kPa.
+	VaporPressureDeficit float64
+	// AbsoluteHumidity in g/m³.
+	AbsoluteHumidity float64
+}
+
+// RelativeHumidity returns the relative humidity which achieves the
+// target at temperature t. It is undefined if t is.
+func (h HumidityTarget) RelativeHumidity(t Temperature) Humidity {
+	if IsUndefined(t) == true {
+		return UndefinedHumidity
+	}
+	es := SaturationVaporPressure(t)
+	if h.AbsoluteHumidity > 0 {
+		return Humidity(100.0 * h.AbsoluteHumidity * (t.Value() + 273.15) / absoluteHumidityFactor / es)
+	}
+	return Humidity(100.0 * (1.0 - h.VaporPressureDeficit/es))
+}
+
+func (h HumidityTarget) sameMeasure(o HumidityTarget) bool {
+	return (h.AbsoluteHumidity > 0) == (o.AbsoluteHumidity > 0)
+}
+
+func (h HumidityTarget) String() string {
+	if h.AbsoluteHumidity > 0 {
+		return fmt.Sprintf("AbsoluteHumidity:%gg/m³", h.AbsoluteHumidity)
+	}
+	return fmt.Sprintf("VPD:%gkPa", h.VaporPressureDeficit)
+}
+
+// EffectiveHumidity returns the relative humidity of s, computed from
+// its temperature if it defines a HumidityTarget.
+func (s State) EffectiveHumidity() Humidity {
+	if s.HumidityTarget == nil {
+		return s.Humidity
+	}
+	return s.HumidityTarget.RelativeHumidity(s.Temperature)
+}
+
+func humidityDefined(s State) bool {
+	return s.HumidityTarget != nil || IsUndefined(s.Humidity) == false
+}
+
+// interpolateHumidity interpolates the humidity of two states. Targets
+// of the same measure are interpolated, otherwise both states are
+// converted to relative humidity, except at the endpoints which keep
+// their own target to follow their temperature.
+func interpolateHumidity(from, to State, completion float64) (Humidity, *HumidityTarget) {
+	if from.HumidityTarget == nil && to.HumidityTarget == nil {
+		return Humidity(interpolate(from.Humidity.Value(), to.Humidity.Value(), completion)), nil
+	}
+	if completion <= 0 && humidityDefined(from) == true {
+		return from.Humidity, from.HumidityTarget
+	}
+	if completion >= 1 && humidityDefined(to) == true {
+		return to.Humidity, to.HumidityTarget
+	}
+	if humidityDefined(to) == false {
+		return UndefinedHumidity, from.HumidityTarget
+	}
+	if humidityDefined(from) == false {
+		return UndefinedHumidity, to.HumidityTarget
+	}
+	if from.HumidityTarget != nil && to.HumidityTarget != nil && from.HumidityTarget.sameMeasure(*to.HumidityTarget) {
+		return UndefinedHumidity, &HumidityTarget{
+			VaporPressureDeficit: interpolate(from.HumidityTarget.VaporPressureDeficit, to.HumidityTarget.VaporPressureDeficit, completion),
+			AbsoluteHumidity:     interpolate(from.HumidityTarget.AbsoluteHumidity, to.HumidityTarget.AbsoluteHumidity, completion),
+		}
+	}
+	return Humidity(interpolate(from.EffectiveHumidity().Value(), to.EffectiveHumidity().Value(), completion)), nil
+}
@@ -0,0 +1,142 @@
+package zeus
+
+import (
+	"math"
+	"time"
+
+	yaml "gopkg.in/yaml.v2"
+
+	. "gopkg.in/check.v1"
+)
+
+type HumiditySuite struct{}
+
+var _ = Suite(&HumiditySuite{})
+
+func (s *HumiditySuite) TestConversions(c *C) {
+	c.Check(math.Abs(SaturationVaporPressure(25)-3.17) < 0.01, Equals, true)
+	c.Check(math.Abs(VaporPressureDeficit(25, 60)-1.27) < 0.01, Equals, true)
+	c.Check(math.Abs(AbsoluteHumidity(25, 60)-13.8) < 0.1, Equals, true)
+
+	for _, t := range []Temperature{18, 22, 26, 30} {
+		for _, h := range []Humidity{40, 60, 80} {
+			vpd := HumidityTarget{VaporPressureDeficit: VaporPressureDeficit(t, h)}
+			c.Check(math.Abs(vpd.RelativeHumidity(t).Value()-h.Value()) < 1e-9, Equals, true)
+			absolute := HumidityTarget{AbsoluteHumidity: AbsoluteHumidity(t, h)}
+			c.Check(math.Abs(absolute.RelativeHumidity(t).Value()-h.Value()) < 1e-9, Equals, true)
+		}
+	}
+	c.Check(IsUndefined(HumidityTarget{VaporPressureDeficit: 1}.RelativeHumidity(UndefinedTemperature)), Equals, true)
+}
+
+func (s *HumiditySuite) TestParsing(c *C) {
+	state := State{}
+	c.Assert(yaml.Unmarshal([]byte("name: day\ntemperature: 25\nvpd: 1.2\n"), &state), IsNil)
+	c.Check(IsUndefined(state.Humidity), Equals, true)
+	c.Assert(state.HumidityTarget, NotNil)
+	c.Check(*state.HumidityTarget, Equals, HumidityTarget{VaporPressureDeficit: 1.2})
+	c.Check(math.Abs(state.EffectiveHumidity().Value()-62.1) < 0.1, Equals, true)
+
+	out, err := yaml.Marshal(state)
+	c.Assert(err, IsNil)
+	c.Check(string(out), Equals, "name: day\ntemperature: 25\nvpd: 1.2\n")
+
+	c.Assert(yaml.Unmarshal([]byte("name: night\ntemperature: 22\nabsolute-humidity: 12\n"), &state), IsNil)
+	c.Check(*state.HumidityTarget, Equals, HumidityTarget{AbsoluteHumidity: 12})
+
+	errors := []struct {
+		Text, Error string
+	}{
+		{"name: day\ntemperature: 25\nvpd: 1.2\nabsolute-humidity: 12\n", "state 'day' defines both vpd and absolute-humidity"},
+		{"name: day\ntemperature: 25\nhumidity: 60\nvpd: 1.2\n", "state 'day' defines both humidity and a vpd or absolute-humidity"},
+		{"name: day\nvpd: 1.2\n", "state 'day' requires a temperature to define a vpd or absolute-humidity"},
+		{"name: day\ntemperature: 25\nvpd: -1\n", "state 'day': invalid vpd -1: should be positive"},
+		{"name: day\ntemperature: 25\nabsolute-humidity: 0\n", "state 'day': invalid absolute-humidity 0: should be positive"},
+	}
+	for _, d := range errors {
+		c.Check(yaml.Unmarshal([]byte(d.Text), &state), ErrorMatches, d.Error)
+	}
+}
+
+func (s *HumiditySuite) TestInterpolation(c *C) {
+	day := State{
+		Name:           "day",
+		Temperature:    26,
+		Humidity:       UndefinedHumidity,
+		HumidityTarget: &HumidityTarget{VaporPressureDeficit: 1.4},
+	}
+	night := State{
+		Name:           "night",
+		Temperature:    20,
+		Humidity:       UndefinedHumidity,
+		HumidityTarget: &HumidityTarget{VaporPressureDeficit: 0.6},
+	}
+	wet := State{Name: "wet", Temperature: 20, Humidity: 80}
+	start := time.Date(2021, 3, 1, 6, 0, 0, 0, time.UTC)
+
+	// targets of the same measure are interpolated and follow the
+	// interpolated temperature
+	i := climateTransition{start: start, from: night, to: day, duration: 10 * time.Minute}
+	middle := i.State(start.Add(5 * time.Minute))
+	c.Check(middle.Temperature, Equals, Temperature(23))
+	c.Assert(middle.HumidityTarget, NotNil)
+	c.Check(math.Abs(middle.HumidityTarget.VaporPressureDeficit-1.0) < 1e-9, Equals, true)
+	c.Check(middle.EffectiveHumidity(), Equals, HumidityTarget{VaporPressureDeficit: 1.0}.RelativeHumidity(23))
+
+	// otherwise relative humidities are interpolated
+	i = climateTransition{start: start, from: wet, to: day, duration: 10 * time.Minute}
+	middle = i.State(start.Add(5 * time.Minute))
+	c.Check(middle.HumidityTarget, IsNil)
+	c.Check(middle.Humidity, Equals, Humidity((80+day.EffectiveHumidity().Value())/2))
+
+	sanitized := SanitizeState(day)
+	c.Check(sanitized.Humidity, Equals, day.EffectiveHumidity())
+
+	o := Override{State: State{Temperature: UndefinedTemperature, Humidity: 70, Wind: UndefinedWind, VisibleLight: UndefinedLight, UVLight: UndefinedLight}}
+	c.Check(o.Apply(day).EffectiveHumidity(), Equals, Humidity(70))
+}
+
+func (s *HumiditySuite) TestTargetFollowsDrift(c *C) {
+	at := func(h int) time.Time { return time.Date(0, 1, 1, h, 0, 0, 0, time.UTC) }
+	states := []State{
+		{Name: "wet", Temperature: 20, Humidity: 80},
+		{
+			Name:           "dry",
+			Temperature:    20,
+			Humidity:       UndefinedHumidity,
+			HumidityTarget: &HumidityTarget{VaporPressureDeficit: 1.0},
+			Drift: &StateDrift{
+				Temperature: &DriftTable{Points: []DriftPoint{{1, 20}, {11, 30}}},
+			},
+		},
+	}
+	transitions := []Transition{
+		{From: "wet", To: "dry", Start: at(6), Duration: time.Hour},
+		{From: "dry", To: "wet", Start: at(18), Duration: time.Hour},
+	}
+	reference := time.Date(2021, 3, 1, 0, 0, 0, 0, time.UTC)
+	i, err := NewClimateInterpoler(states, transitions, reference)
+	c.Assert(err, IsNil)
+
+	// the target of the state entered from, and leaving to, a
+	// relative humidity state follows the drifting temperature.
+	for _, day := range []int{1, 2, 6, 11} {
+		t := reference.AddDate(0, 0, day-1).Add(12 * time.Hour)
+		current, _, _ := i.CurrentInterpolation(t)
+		res := current.State(t)
+		c.Assert(res.HumidityTarget, NotNil, Commentf("day %d", day))
+		c.Check(res.HumidityTarget.VaporPressureDeficit, Equals, 1.0)
+		c.Check(res.EffectiveHumidity(), Equals, HumidityTarget{VaporPressureDeficit: 1.0}.RelativeHumidity(res.Temperature), Commentf("day %d", day))
+	}
+	t := reference.AddDate(0, 0, 10).Add(12 * time.Hour)
+	current, _, _ := i.CurrentInterpolation(t)
+	c.Check(current.State(t).Temperature, Equals, Temperature(30))
+	c.Check(math.Abs(current.State(t).EffectiveHumidity().Value()-76.4) < 0.1, Equals, true)
+
+	// the transitions still interpolate the relative humidity
+	t = reference.AddDate(0, 0, 10).Add(18*time.Hour + 30*time.Minute)
+	current, _, _ = i.CurrentInterpolation(t)
+	res := current.State(t)
+	c.Check(res.HumidityTarget, IsNil)
+	c.Check(res.Humidity, Equals, Humidity((HumidityTarget{VaporPressureDeficit: 1.0}.RelativeHumidity(30).Value()+80)/2))
+}
@@ -0,0 +1,107 @@
+package zeus
+
+import (
+	"fmt"
+	"math"
+)
+
+// UnitLimits are the minimal and maximal values an actuator can
+// reach.
+type UnitLimits struct {
+	Min float64 `yaml:"min"`
+	Max float64 `yaml:"max"`
+}
+
+func unitLimits(u BoundedUnit) UnitLimits {
+	return UnitLimits{Min: u.MinValue(), Max: u.MaxValue()}
+}
+
+// Clamp returns v limited to [Min,Max].
+func (l UnitLimits) Clamp(v float64) float64 {
+	return math.Min(math.Max(v, l.Min), l.Max)
+}
+
+// HardwareLimits are the values the actuators of a zone can
+// reach. They default to the limits of the units.
+type HardwareLimits struct {
+	Temperature  UnitLimits `yaml:"temperature"`
+	Humidity     UnitLimits `yaml:"humidity"`
+	Wind         UnitLimits `yaml:"wind"`
+	VisibleLight UnitLimits `yaml:"visible-light"`
+	UVLight      UnitLimits `yaml:"uv-light"`
+}
+
+// DefaultHardwareLimits returns the limits of the standard zeus
+// hardware.
+func DefaultHardwareLimits() HardwareLimits {
+	return HardwareLimits{
+		Temperature:  unitLimits(Temperature(0)),
+		Humidity:     unitLimits(Humidity(0)),
+		Wind:         unitLimits(Wind(0)),
+		VisibleLight: unitLimits(Light(0)),
+		UVLight:      unitLimits(Light(0)),
+	}
+}
+
+func (l *HardwareLimits) UnmarshalYAML(unmarshal func(interface{}) error) error {
+	type plain HardwareLimits
+	res := plain(DefaultHardwareLimits())
+	if err := unmarshal(&res); err != nil {
+		return err
+	}
+	*l = HardwareLimits(res)
+	return l.Check()
+}
+
+// Channel returns the limits of a channel. Channels without
+// configurable limits are limited by their unit.
+func (l HardwareLimits) Channel(c *Channel) UnitLimits {
+	switch c {
+	case TemperatureChannel:
+		return l.Temperature
+	case HumidityChannel:
+		return l.Humidity
+	case WindChannel:
+		return l.Wind
+	case VisibleLightChannel:
+		return l.VisibleLight
+	case UVLightChannel:
+		return l.UVLight
+	}
+	return unitLimits(c.Unit(0))
+}
+
+// Check returns an error if any limits are empty, or if percentage
+// values are outside of [0,100].
+func (l HardwareLimits) Check() error {
+	for _, c := range builtinChannels {
+		limits := l.Channel(c)
+		if limits.Min >= limits.Max {
+			return fmt.Errorf("invalid %s limits [%g,%g]: minimum should be lower than maximum", c.Name, limits.Min, limits.Max)
+		}
+		if c != TemperatureChannel && (limits.Min < 0 || limits.Max > 100) {
+			return fmt.Errorf("invalid %s limits [%g,%g]: should be within [0,100]", c.Name, limits.Min, limits.Max)
+		}
+	}
+	return nil
+}
+
+// CheckClimate returns an error if a state of climate asks for a value
+// the hardware cannot reach.
+func (l HardwareLimits) CheckClimate(climate ZoneClimate) error {
+	for i, s := range climate.States {
+		values := stateValues(fmt.Sprintf("states[%d]", i), s)
+		for _, c := range channels {
+			limits := l.Channel(c)
+			for _, v := range values[c.Name] {
+				if v.value.Value() < limits.Min {
+					return fmt.Errorf("state '%s': %s is below the hardware minimum %g", s.Name, v.describe(c.Name), limits.Min)
+				}
+				if v.value.Value() > limits.Max {
+					return fmt.Errorf("state '%s': %s is above the hardware maximum %g", s.Name, v.describe(c.Name), limits.Max)
+				}
+			}
+		}
+	}
+	return nil
+}
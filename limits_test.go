@@ -0,0 +1,50 @@
+package zeus
+
+import (
+	yaml "gopkg.in/yaml.v2"
+
+	. "gopkg.in/check.v1"
+)
+
+type LimitsSuite struct{}
+
+var _ = Suite(&LimitsSuite{})
+
+func (s *LimitsSuite) TestParsing(c *C) {
+	limits := HardwareLimits{}
+	c.Assert(yaml.Unmarshal([]byte("temperature: {min: 8, max: 30}\nuv-light: {max: 50}\n"), &limits), IsNil)
+	expected := DefaultHardwareLimits()
+	expected.Temperature = UnitLimits{Min: 8, Max: 30}
+	expected.UVLight.Max = 50
+	c.Check(limits, Equals, expected)
+	c.Check(limits.Temperature.Clamp(5), Equals, 8.0)
+	c.Check(limits.Temperature.Clamp(35), Equals, 30.0)
+
+	errors := []struct {
+		Text, Error string
+	}{
+		{"temperature: {min: 30, max: 20}", `invalid temperature limits \[30,20\]: minimum should be lower than maximum`},
+		{"wind: {min: -10}", `invalid wind limits \[-10,100\]: should be within \[0,100\]`},
+	}
+	for _, d := range errors {
+		c.Check(yaml.Unmarshal([]byte(d.Text), &limits), ErrorMatches, d.Error)
+	}
+}
+
+func (s *LimitsSuite) TestCheckClimate(c *C) {
+	climate := ZoneClimate{
+		States: []State{
+			{Name: "cold", Temperature: 10, Humidity: 60, Wind: UndefinedWind, VisibleLight: UndefinedLight, UVLight: UndefinedLight},
+			{
+				Name: "day", Temperature: 26, Humidity: 60, Wind: UndefinedWind, VisibleLight: UndefinedLight, UVLight: UndefinedLight,
+				Drift: &StateDrift{UVLight: &DriftTable{Points: []DriftPoint{{1, 20}, {30, 80}}}},
+			},
+		},
+	}
+	limits := DefaultHardwareLimits()
+	c.Check(limits.CheckClimate(climate), ErrorMatches, "state 'cold': temperature 10 is below the hardware minimum 15")
+	limits.Temperature.Min = 8
+	c.Check(limits.CheckClimate(climate), IsNil)
+	limits.UVLight.Max = 50
+	c.Check(limits.CheckClimate(climate), ErrorMatches, "state 'day': uv-light 80 on day 30 is above the hardware maximum 50")
+}
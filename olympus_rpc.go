@@ -29,6 +29,13 @@ type StateReport struct {
 	Next           *State
 	NextEnd        *State
 	NextTime       *time.Time
+	// End is the scheduled end of the experiment, if any, and Ended
+	// is true once it is reached.
+	End   *time.Time
+	Ended bool
+	// Override is the manual override of the zone climate, if any.
+	// Current already includes it.
+	Override *Override
 }
 
 func ZoneIdentifier(host, name string) string {
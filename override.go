@@ -0,0 +1,69 @@
+package zeus
+
+import (
+	"fmt"
+	"time"
+)
+
+// Override temporarily forces some fields of the State of a zone,
+// until a given time. Undefined fields of State keep following the
+// schedule.
+type Override struct {
+	State State
+	Until time.Time
+}
+
+// NewOverride returns an Override of the defined fields of s, for
+// duration from now.
+func NewOverride(s State, now time.Time, duration time.Duration) (*Override, error) {
+	if duration <= 0 {
+		return nil, fmt.Errorf("invalid override duration %s: should be positive", duration)
+	}
+	defined := false
+	for _, c := range channels {
+		defined = defined || IsUndefined(c.Value(s)) == false
+	}
+	if defined == false {
+		return nil, fmt.Errorf("override does not define any value")
+	}
+	return &Override{State: s, Until: now.Add(duration)}, nil
+}
+
+// Active returns true if the override is still in effect at t.
+func (o *Override) Active(t time.Time) bool {
+	return o != nil && t.Before(o.Until)
+}
+
+// Apply returns s with the fields defined by the override
+// replaced. The name of the scheduled State is kept.
+func (o *Override) Apply(s State) State {
+	if o == nil {
+		return s
+	}
+	for _, c := range channels {
+		if v := c.Value(o.State); IsUndefined(v) == false {
+			c.set(&s, v.Value())
+		}
+	}
+	if IsUndefined(o.State.Humidity) == false {
+		s.HumidityTarget = nil
+	}
+	return s
+}
+
+func (o Override) String() string {
+	res := ""
+	add := func(name string, u BoundedUnit) {
+		if IsUndefined(u) == true {
+			return
+		}
+		if len(res) > 0 {
+			res += " "
+		}
+		res += fmt.Sprintf("%s:%v", name, u.Value())
+	}
+	for _, c := range channels {
+		add(c.Name, c.Value(o.State))
+	}
+	return fmt.Sprintf("override {%s} until %s", res, o.Until.Format(time.RFC3339))
+}
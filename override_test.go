@@ -0,0 +1,46 @@
+package zeus
+
+import (
+	"time"
+
+	. "gopkg.in/check.v1"
+)
+
+type OverrideSuite struct{}
+
+var _ = Suite(&OverrideSuite{})
+
+func (s *OverrideSuite) TestOverride(c *C) {
+	now := time.Date(2021, 3, 1, 12, 0, 0, 0, time.UTC)
+	partial := State{
+		Temperature:  UndefinedTemperature,
+		Humidity:     70,
+		Wind:         UndefinedWind,
+		VisibleLight: 100,
+		UVLight:      UndefinedLight,
+	}
+	o, err := NewOverride(partial, now, 30*time.Minute)
+	c.Assert(err, IsNil)
+	c.Check(o.Until, Equals, now.Add(30*time.Minute))
+	c.Check(o.Active(now), Equals, true)
+	c.Check(o.Active(o.Until), Equals, false)
+	c.Check(o.String(), Equals, "override {humidity:70 visible-light:100} until 2021-03-01T12:30:00Z")
+
+	scheduled := State{Name: "night", Temperature: 22, Humidity: 50, Wind: 20, VisibleLight: 0, UVLight: 0}
+	c.Check(o.Apply(scheduled), Equals, State{Name: "night", Temperature: 22, Humidity: 70, Wind: 20, VisibleLight: 100, UVLight: 0})
+
+	var none *Override
+	c.Check(none.Active(now), Equals, false)
+	c.Check(none.Apply(scheduled), Equals, scheduled)
+
+	_, err = NewOverride(partial, now, 0)
+	c.Check(err, ErrorMatches, "invalid override duration 0s: should be positive")
+	_, err = NewOverride(State{
+		Temperature:  UndefinedTemperature,
+		Humidity:     UndefinedHumidity,
+		Wind:         UndefinedWind,
+		VisibleLight: UndefinedLight,
+		UVLight:      UndefinedLight,
+	}, now, time.Hour)
+	c.Check(err, ErrorMatches, "override does not define any value")
+}
@@ -0,0 +1,150 @@
+package zeus
+
+import (
+	"fmt"
+	"strings"
+	"time"
+)
+
+// Recurrence restricts the days on which a recurring Transition
+// occurs. Days are experiment days, starting at 1. All set
+// conditions must be met for the transition to occur.
+type Recurrence struct {
+	// Every only allows one day every Every days, starting from
+	// FromDay, or the first day of the experiment.
+	Every int
+	// Weekdays only allows the given days of the week. Empty means
+	// any day.
+	Weekdays []time.Weekday
+	// FromDay and ToDay, if non-zero, restricts the transition to
+	// the given inclusive range of days.
+	FromDay, ToDay int
+}
+
+var weekdayNames = map[string]time.Weekday{
+	"sun": time.Sunday,
+	"mon": time.Monday,
+	"tue": time.Tuesday,
+	"wed": time.Wednesday,
+	"thu": time.Thursday,
+	"fri": time.Friday,
+	"sat": time.Saturday,
+}
+
+func parseWeekday(s string) (time.Weekday, error) {
+	s = strings.ToLower(s)
+	for name, d := range weekdayNames {
+		if s == name || s == strings.ToLower(d.String()) {
+			return d, nil
+		}
+	}
+	return time.Sunday, fmt.Errorf("invalid weekday '%s'", s)
+}
+
+func formatWeekday(d time.Weekday) string {
+	return strings.ToLower(d.String()[:3])
+}
+
+func (r *Recurrence) Check() error {
+	if r.Every < 0 || r.Every > maxRecurrenceSearch {
+		return fmt.Errorf("invalid recurrence period %d: should be in [1,%d]", r.Every, maxRecurrenceSearch)
+	}
+	if r.FromDay < 0 || r.ToDay < 0 {
+		return fmt.Errorf("invalid recurrence day range [%d,%d]: days start at 1", r.FromDay, r.ToDay)
+	}
+	if r.ToDay != 0 && r.ToDay < r.FromDay {
+		return fmt.Errorf("invalid recurrence day range [%d,%d]", r.FromDay, r.ToDay)
+	}
+	return nil
+}
+
+// Matches returns true if the recurrence allows the given experiment
+// day, which is a weekday.
+func (r *Recurrence) Matches(day int, weekday time.Weekday) bool {
+	if r.FromDay != 0 && day < r.FromDay {
+		return false
+	}
+	if r.ToDay != 0 && day > r.ToDay {
+		return false
+	}
+	if r.Every > 1 {
+		first := 1
+		if r.FromDay > 0 {
+			first = r.FromDay
+		}
+		if (day-first)%r.Every != 0 {
+			return false
+		}
+	}
+	if len(r.Weekdays) == 0 {
+		return true
+	}
+	for _, d := range r.Weekdays {
+		if d == weekday {
+			return true
+		}
+	}
+	return false
+}
+
+func (r *Recurrence) String() string {
+	parts := []string{}
+	if r.Every > 1 {
+		parts = append(parts, fmt.Sprintf("every %d days", r.Every))
+	}
+	if len(r.Weekdays) > 0 {
+		days := make([]string, 0, len(r.Weekdays))
+		for _, d := range r.Weekdays {
+			days = append(days, formatWeekday(d))
+		}
+		parts = append(parts, "on "+strings.Join(days, ","))
+	}
+	if r.FromDay > 0 {
+		parts = append(parts, fmt.Sprintf("from day %d", r.FromDay))
+	}
+	if r.ToDay > 0 {
+		parts = append(parts, fmt.Sprintf("to day %d", r.ToDay))
+	}
+	if len(parts) == 0 {
+		return "every day"
+	}
+	return strings.Join(parts, " ")
+}
+
+type recurrenceShadow struct {
+	Every    int      `yaml:"every,omitempty"`
+	Weekdays []string `yaml:"weekdays,omitempty"`
+	FromDay  int      `yaml:"from-day,omitempty"`
+	ToDay    int      `yaml:"to-day,omitempty"`
+}
+
+func (r *Recurrence) UnmarshalYAML(unmarshal func(interface{}) error) error {
+	shadow := recurrenceShadow{}
+	if err := unmarshal(&shadow); err != nil {
+		return err
+	}
+	r.Every = shadow.Every
+	r.FromDay = shadow.FromDay
+	r.ToDay = shadow.ToDay
+	r.Weekdays = nil
+	for _, name := range shadow.Weekdays {
+		d, err := parseWeekday(name)
+		if err != nil {
+			return err
+		}
+		r.Weekdays = append(r.Weekdays, d)
+	}
+	return r.Check()
+}
+
+func (r Recurrence) MarshalYAML() (interface{}, error) {
+	res := recurrenceShadow{
+		Every:   r.Every,
+		FromDay: r.FromDay,
+		ToDay:   r.ToDay,
+	}
+	for _, d := range r.Weekdays {
+		res.Weekdays = append(res.Weekdays, formatWeekday(d))
+	}
+	return res, nil
+}
@@ -0,0 +1,87 @@
+package zeus
+
+import (
+	"time"
+
+	. "gopkg.in/check.v1"
+	yaml "gopkg.in/yaml.v2"
+)
+
+type RecurrenceSuite struct{}
+
+var _ = Suite(&RecurrenceSuite{})
+
+func (s *RecurrenceSuite) TestParsing(c *C) {
+	testdata := []struct {
+		Text       string
+		Recurrence Recurrence
+		String     string
+	}{
+		{
+			Text:       "every: 3",
+			Recurrence: Recurrence{Every: 3},
+			String:     "every 3 days",
+		},
+		{
+			Text:       "weekdays: [mon, Wednesday, FRI]",
+			Recurrence: Recurrence{Weekdays: []time.Weekday{time.Monday, time.Wednesday, time.Friday}},
+			String:     "on mon,wed,fri",
+		},
+		{
+			Text:       "from-day: 10\nto-day: 20",
+			Recurrence: Recurrence{FromDay: 10, ToDay: 20},
+			String:     "from day 10 to day 20",
+		},
+	}
+	for _, d := range testdata {
+		res := Recurrence{}
+		err := yaml.Unmarshal([]byte(d.Text), &res)
+		if c.Check(err, IsNil) == false {
+			continue
+		}
+		c.Check(res, DeepEquals, d.Recurrence)
+		c.Check(res.String(), Equals, d.String)
+		data, err := yaml.Marshal(res)
+		c.Check(err, IsNil)
+		back := Recurrence{}
+		c.Check(yaml.Unmarshal(data, &back), IsNil)
+		c.Check(back, DeepEquals, d.Recurrence)
+	}
+
+	errordata := []struct {
+		Text, Error string
+	}{
+		{"weekdays: [mon, foo]", "invalid weekday 'foo'"},
+		{"every: -1", "invalid recurrence period -1: .*"},
+		{"from-day: 20\nto-day: 10", "invalid recurrence day range \\[20,10\\]"},
+		{"from-day: -2", "invalid recurrence day range \\[-2,0\\]: days start at 1"},
+	}
+	for _, d := range errordata {
+		res := Recurrence{}
+		c.Check(yaml.Unmarshal([]byte(d.Text), &res), ErrorMatches, d.Error)
+	}
+}
+
+func (s *RecurrenceSuite) TestMatching(c *C) {
+	testdata := []struct {
+		Recurrence Recurrence
+		Day        int
+		Weekday    time.Weekday
+		Expected   bool
+	}{
+		{Recurrence{}, 42, time.Monday, true},
+		{Recurrence{Every: 3}, 1, time.Monday, true},
+		{Recurrence{Every: 3}, 3, time.Monday, false},
+		{Recurrence{Every: 3}, 4, time.Monday, true},
+		{Recurrence{Every: 2, FromDay: 10}, 10, time.Monday, true},
+		{Recurrence{Every: 2, FromDay: 10}, 11, time.Monday, false},
+		{Recurrence{Every: 2, FromDay: 10}, 8, time.Monday, false},
+		{Recurrence{FromDay: 10, ToDay: 20}, 20, time.Monday, true},
+		{Recurrence{FromDay: 10, ToDay: 20}, 21, time.Monday, false},
+		{Recurrence{Weekdays: []time.Weekday{time.Monday, time.Friday}}, 3, time.Friday, true},
+		{Recurrence{Weekdays: []time.Weekday{time.Monday, time.Friday}}, 3, time.Sunday, false},
+	}
+	for _, d := range testdata {
+		c.Check(d.Recurrence.Matches(d.Day, d.Weekday), Equals, d.Expected, Commentf("%s on day %d (%s)", &d.Recurrence, d.Day, d.Weekday))
+	}
+}
@@ -0,0 +1,536 @@
+package zeus
+
+import (
+	"fmt"
+	"io/ioutil"
+	"path/filepath"
+	"regexp"
+	"strings"
+
+	"gopkg.in/yaml.v2"
+)
+
+// Season files can be composed from other files and templates. They
+// are expanded when read, so only expanded SeasonFile are handled by
+// the rest of the program:
+//
+//   - a top-level `include:` lists season fragments, whose content is
+//     merged before the content of the file.
+//   - a State can declare `extends: <other state>` of the same zone,
+//     and only define the fields that differ.
+//   - a zone can declare `template: <name>` and `parameters:`, to be
+//     instantiated from a top-level `templates:` entry, where
+//     `${parameter}` are replaced by their values.
+
+type seasonDocument struct {
+	content yaml.MapSlice
+	// composed is true if the document uses any composition feature.
+	composed bool
+	// sources are the files merged in the document, by increasing
+	// priority.
+	sources []seasonSource
+	// aliases maps the path of a zone instantiated from a template,
+	// or of a state extending another one, to the path of the node it
+	// is copied from.
+	aliases map[string]string
+}
+
+// seasonSource holds the positions of the nodes of a file composing
+// a season.
+type seasonSource struct {
+	filename  string
+	positions yamlPositions
+}
+
+// seasonFileError is an error in a file composing a season.
+type seasonFileError struct {
+	filename string
+	err      error
+}
+
+func (e seasonFileError) Error() string {
+	return e.err.Error()
+}
+
+func yamlKey(item yaml.MapItem) string {
+	key, ok := item.Key.(string)
+	if ok == false {
+		return fmt.Sprintf("%v", item.Key)
+	}
+	return key
+}
+
+func yamlLookup(m yaml.MapSlice, key string) (interface{}, bool) {
+	for _, item := range m {
+		if yamlKey(item) == key {
+			return item.Value, true
+		}
+	}
+	return nil, false
+}
+
+func yamlWithout(m yaml.MapSlice, keys ...string) yaml.MapSlice {
+	res := make(yaml.MapSlice, 0, len(m))
+	for _, item := range m {
+		removed := false
+		for _, key := range keys {
+			removed = removed || yamlKey(item) == key
+		}
+		if removed == false {
+			res = append(res, item)
+		}
+	}
+	return res
+}
+
+// copyYAML returns a deep copy of a generic YAML value.
+func copyYAML(value interface{}) interface{} {
+	switch v := value.(type) {
+	case yaml.MapSlice:
+		res := make(yaml.MapSlice, 0, len(v))
+		for _, item := range v {
+			res = append(res, yaml.MapItem{Key: item.Key, Value: copyYAML(item.Value)})
+		}
+		return res
+	case []interface{}:
+		res := make([]interface{}, 0, len(v))
+		for _, item := range v {
+			res = append(res, copyYAML(item))
+		}
+		return res
+	default:
+		return v
+	}
+}
+
+// mergeYAML merges override over base: mappings are merged key by key,
+// any other value of override replaces the one of base.
+func mergeYAML(base, override interface{}) interface{} {
+	b, bIsMap := base.(yaml.MapSlice)
+	o, oIsMap := override.(yaml.MapSlice)
+	if bIsMap == false || oIsMap == false {
+		return copyYAML(override)
+	}
+	res := copyYAML(b).(yaml.MapSlice)
+	for _, item := range o {
+		found := false
+		for i := range res {
+			if yamlKey(res[i]) == yamlKey(item) {
+				res[i].Value = mergeYAML(res[i].Value, item.Value)
+				found = true
+				break
+			}
+		}
+		if found == false {
+			res = append(res, yaml.MapItem{Key: item.Key, Value: copyYAML(item.Value)})
+		}
+	}
+	return res
+}
+
+func readSeasonDocument(filename string, including []string) (*seasonDocument, error) {
+	for _, f := range including {
+		if f == filename {
+			return nil, fmt.Errorf("include cycle: %s -> %s", strings.Join(including, " -> "), filename)
+		}
+	}
+	data, err := ioutil.ReadFile(filename)
+	if err != nil {
+		return nil, err
+	}
+	content := yaml.MapSlice{}
+	if err := yaml.Unmarshal(data, &content); err != nil {
+		return nil, seasonFileError{filename: filename, err: err}
+	}
+	source := seasonSource{filename: filename, positions: parseYAMLPositions(data)}
+	included, ok := yamlLookup(content, "include")
+	if ok == false {
+		return &seasonDocument{content: content, sources: []seasonSource{source}}, nil
+	}
+
+	var files []string
+	switch v := included.(type) {
+	case string:
+		files = []string{v}
+	case []interface{}:
+		for _, f := range v {
+			name, ok := f.(string)
+			if ok == false {
+				return nil, fmt.Errorf("%s: invalid include '%v': should be a filename", filename, f)
+			}
+			files = append(files, name)
+		}
+	default:
+		return nil, fmt.Errorf("%s: invalid include '%v': should be a filename or a list of filenames", filename, included)
+	}
+
+	res := &seasonDocument{content: yaml.MapSlice{}, composed: true}
+	for _, f := range files {
+		if filepath.IsAbs(f) == false {
+			f = filepath.Join(filepath.Dir(filename), f)
+		}
+		fragment, err := readSeasonDocument(f, append(including, filename))
+		if err != nil {
+			return nil, err
+		}
+		res.content = mergeYAML(res.content, fragment.content).(yaml.MapSlice)
+		res.sources = append(res.sources, fragment.sources...)
+	}
+	res.content = mergeYAML(res.content, yamlWithout(content, "include")).(yaml.MapSlice)
+	res.sources = append(res.sources, source)
+	return res, nil
+}
+
+var templateParameterRx = regexp.MustCompile(`\$\{([^}]*)\}`)
+
+// substituteYAML replaces all `${parameter}` in the string values of
+// value. A value consisting only of a parameter takes the value of the
+// parameter, keeping its type.
+func substituteYAML(value interface{}, parameters map[string]interface{}) (interface{}, error) {
+	switch v := value.(type) {
+	case yaml.MapSlice:
+		res := make(yaml.MapSlice, 0, len(v))
+		for _, item := range v {
+			substituted, err := substituteYAML(item.Value, parameters)
+			if err != nil {
+				return nil, err
+			}
+			res = append(res, yaml.MapItem{Key: item.Key, Value: substituted})
+		}
+		return res, nil
+	case []interface{}:
+		res := make([]interface{}, 0, len(v))
+		for _, item := range v {
+			substituted, err := substituteYAML(item, parameters)
+			if err != nil {
+				return nil, err
+			}
+			res = append(res, substituted)
+		}
+		return res, nil
+	case string:
+		var rerr error
+		lookup := func(name string) interface{} {
+			value, ok := parameters[name]
+			if ok == false && rerr == nil {
+				rerr = fmt.Errorf("undefined template parameter '%s'", name)
+			}
+			return value
+		}
+		if m := templateParameterRx.FindStringSubmatch(v); m != nil && m[0] == v {
+			res := lookup(m[1])
+			return copyYAML(res), rerr
+		}
+		res := templateParameterRx.ReplaceAllStringFunc(v, func(p string) string {
+			return fmt.Sprintf("%v", lookup(templateParameterRx.FindStringSubmatch(p)[1]))
+		})
+		return res, rerr
+	default:
+		return v, nil
+	}
+}
+
+func yamlParameters(value interface{}, what string) (map[string]interface{}, error) {
+	res := map[string]interface{}{}
+	if value == nil {
+		return res, nil
+	}
+	m, ok := value.(yaml.MapSlice)
+	if ok == false {
+		return nil, fmt.Errorf("invalid %s: should be a mapping", what)
+	}
+	for _, item := range m {
+		res[yamlKey(item)] = item.Value
+	}
+	return res, nil
+}
+
+func instantiateZone(zone yaml.MapSlice, templates yaml.MapSlice) (yaml.MapSlice, error) {
+	name, ok := yamlLookup(zone, "template")
+	if ok == false {
+		if _, ok := yamlLookup(zone, "parameters"); ok == true {
+			return nil, fmt.Errorf("parameters require a template")
+		}
+		return zone, nil
+	}
+	templateValue, ok := yamlLookup(templates, fmt.Sprintf("%v", name))
+	if ok == false {
+		return nil, fmt.Errorf("undefined template '%v'", name)
+	}
+	template, ok := templateValue.(yaml.MapSlice)
+	if ok == false {
+		return nil, fmt.Errorf("invalid template '%v': should be a mapping", name)
+	}
+
+	defaults, _ := yamlLookup(template, "parameters")
+	parameters, err := yamlParameters(defaults, fmt.Sprintf("template '%v' parameters", name))
+	if err != nil {
+		return nil, err
+	}
+	values, _ := yamlLookup(zone, "parameters")
+	zoneParameters, err := yamlParameters(values, "zone parameters")
+	if err != nil {
+		return nil, err
+	}
+	for k, v := range zoneParameters {
+		parameters[k] = v
+	}
+
+	instance, err := substituteYAML(yamlWithout(template, "parameters"), parameters)
+	if err != nil {
+		return nil, fmt.Errorf("template '%v': %s", name, err)
+	}
+	return mergeYAML(instance, yamlWithout(zone, "template", "parameters")).(yaml.MapSlice), nil
+}
+
+func stateName(state yaml.MapSlice) string {
+	name, _ := yamlLookup(state, "name")
+	return fmt.Sprintf("%v", name)
+}
+
+// resolveExtends replaces the states of a zone which extend another
+// state by their full definition.
+func resolveExtends(states []interface{}) ([]interface{}, bool, error) {
+	byName := map[string]yaml.MapSlice{}
+	for _, s := range states {
+		if m, ok := s.(yaml.MapSlice); ok == true {
+			byName[stateName(m)] = m
+		}
+	}
+
+	resolved := map[string]yaml.MapSlice{}
+	var resolve func(state yaml.MapSlice, chain []string) (yaml.MapSlice, error)
+	resolve = func(state yaml.MapSlice, chain []string) (yaml.MapSlice, error) {
+		name := stateName(state)
+		if r, ok := resolved[name]; ok == true {
+			return r, nil
+		}
+		baseName, ok := yamlLookup(state, "extends")
+		if ok == false {
+			return state, nil
+		}
+		for _, c := range chain {
+			if c == name {
+				return nil, fmt.Errorf("state inheritance cycle: %s -> %s", strings.Join(chain, " -> "), name)
+			}
+		}
+		base, ok := byName[fmt.Sprintf("%v", baseName)]
+		if ok == false {
+			return nil, fmt.Errorf("state '%s' extends undefined state '%v'", name, baseName)
+		}
+		base, err := resolve(base, append(chain, name))
+		if err != nil {
+			return nil, err
+		}
+		res := mergeYAML(yamlWithout(base, "name"), yamlWithout(state, "extends")).(yaml.MapSlice)
+		resolved[name] = res
+		return res, nil
+	}
+
+	res := make([]interface{}, 0, len(states))
+	extended := false
+	for _, s := range states {
+		m, ok := s.(yaml.MapSlice)
+		if ok == false {
+			res = append(res, s)
+			continue
+		}
+		if _, ok := yamlLookup(m, "extends"); ok == true {
+			extended = true
+		}
+		r, err := resolve(m, nil)
+		if err != nil {
+			return nil, false, err
+		}
+		// keeps the name first for readability
+		name, _ := yamlLookup(r, "name")
+		res = append(res, append(yaml.MapSlice{{Key: "name", Value: name}}, yamlWithout(r, "name")...))
+	}
+	return res, extended, nil
+}
+
+// expand instantiates the templates and resolves the state
+// inheritance of the document.
+func (d *seasonDocument) expand() error {
+	templatesValue, hasTemplates := yamlLookup(d.content, "templates")
+	templates, ok := templatesValue.(yaml.MapSlice)
+	if hasTemplates == true {
+		if ok == false {
+			return fmt.Errorf("invalid templates: should be a mapping")
+		}
+		d.composed = true
+		d.content = yamlWithout(d.content, "templates")
+	}
+
+	for i, item := range d.content {
+		if yamlKey(item) != "zones" {
+			continue
+		}
+		zones, ok := item.Value.(yaml.MapSlice)
+		if ok == false {
+			return nil
+		}
+		for j, zoneItem := range zones {
+			zone, ok := zoneItem.Value.(yaml.MapSlice)
+			if ok == false {
+				continue
+			}
+			zonePath := "zones." + yamlKey(zoneItem)
+			if name, ok := yamlLookup(zone, "template"); ok == true {
+				d.composed = true
+				d.alias(zonePath, fmt.Sprintf("templates.%v", name))
+			}
+			zone, err := instantiateZone(zone, templates)
+			if err != nil {
+				return fmt.Errorf("zone '%s': %s", yamlKey(zoneItem), err)
+			}
+			for k, zoneField := range zone {
+				if yamlKey(zoneField) != "states" {
+					continue
+				}
+				states, ok := zoneField.Value.([]interface{})
+				if ok == false {
+					continue
+				}
+				d.aliasExtendedStates(zonePath+".states", states)
+				states, extended, err := resolveExtends(states)
+				if err != nil {
+					return fmt.Errorf("zone '%s': %s", yamlKey(zoneItem), err)
+				}
+				d.composed = d.composed || extended
+				zone[k].Value = states
+			}
+			zones[j].Value = zone
+		}
+		d.content[i].Value = zones
+	}
+	return nil
+}
+
+func (d *seasonDocument) alias(path, original string) {
+	if d.aliases == nil {
+		d.aliases = map[string]string{}
+	}
+	d.aliases[path] = original
+}
+
+// aliasExtendedStates aliases the states extending another one to
+// their base state.
+func (d *seasonDocument) aliasExtendedStates(path string, states []interface{}) {
+	indexes := map[string]int{}
+	for i, s := range states {
+		if m, ok := s.(yaml.MapSlice); ok == true {
+			indexes[stateName(m)] = i
+		}
+	}
+	for i, s := range states {
+		m, ok := s.(yaml.MapSlice)
+		if ok == false {
+			continue
+		}
+		base, ok := yamlLookup(m, "extends")
+		if ok == false {
+			continue
+		}
+		if j, ok := indexes[fmt.Sprintf("%v", base)]; ok == true {
+			d.alias(fmt.Sprintf("%s[%d]", path, i), fmt.Sprintf("%s[%d]", path, j))
+		}
+	}
+}
+
+// find returns the position of path in the sources, following
+// aliases.
+func (d *seasonDocument) find(path string, depth int) (string, int, bool) {
+	// sequences are replaced as a whole when merged, their items
+	// come from the last file defining the sequence.
+	sources := d.sources
+	sequence := ""
+	if idx := strings.Index(path, "["); idx >= 0 {
+		for i := len(sources) - 1; i >= 0; i-- {
+			if _, ok := sources[i].positions[path[:idx]]; ok == true {
+				sources = sources[i : i+1]
+				sequence = path[:idx]
+				break
+			}
+		}
+	}
+	for i := len(sources) - 1; i >= 0; i-- {
+		if line, ok := sources[i].positions[path]; ok == true {
+			return sources[i].filename, line, true
+		}
+	}
+	if depth >= len(d.aliases) {
+		return "", 0, false
+	}
+	// a template is copied before the states are extended, its
+	// aliases are tried first. A sequence written in a file is not
+	// copied from a template.
+	prefixes := []string{}
+	for prefix := path; len(prefix) >= len(sequence) && len(prefix) > 0; prefix = yamlParent(prefix) {
+		prefixes = append([]string{prefix}, prefixes...)
+	}
+	for _, prefix := range prefixes {
+		original, ok := d.aliases[prefix]
+		if ok == false {
+			continue
+		}
+		if filename, line, ok := d.find(original+path[len(prefix):], depth+1); ok == true {
+			return filename, line, true
+		}
+	}
+	return "", 0, false
+}
+
+// position returns the file and line of the node at path in the
+// expanded document, or of its closest parent. The line is 0 if none
+// is found.
+func (d *seasonDocument) position(path string) (string, int) {
+	for ; len(path) > 0; path = yamlParent(path) {
+		if filename, line, ok := d.find(path, 0); ok == true {
+			return filename, line
+		}
+	}
+	return d.sources[len(d.sources)-1].filename, 0
+}
+
+// expandedPosition returns the file and line of the node at line in
+// the expanded content data.
+func (d *seasonDocument) expandedPosition(data []byte, line int) (string, int) {
+	if d.composed == false {
+		return d.sources[len(d.sources)-1].filename, line
+	}
+	path := ""
+	for p, l := range parseYAMLPositions(data) {
+		if l == line && len(p) > len(path) {
+			path = p
+		}
+	}
+	return d.position(path)
+}
+
+// readExpandedSeason reads a season file and returns its expanded
+// content. If no composition feature is used, the original content is
+// returned.
+func readExpandedSeason(filename string) (*seasonDocument, []byte, error) {
+	d, err := readSeasonDocument(filename, nil)
+	if err != nil {
+		return nil, nil, err
+	}
+	if err := d.expand(); err != nil {
+		return nil, nil, err
+	}
+	if d.composed == false {
+		data, err := ioutil.ReadFile(filename)
+		return d, data, err
+	}
+	data, err := yaml.Marshal(d.content)
+	return d, data, err
+}
+
+// expandSeasonFile returns the content of a season file with all its
+// includes, templates and state inheritance expanded. If none is used,
+// the original content is returned.
+func expandSeasonFile(filename string) ([]byte, error) {
+	_, data, err := readExpandedSeason(filename)
+	return data, err
+}
@@ -0,0 +1,214 @@
+package zeus
+
+import (
+	"bytes"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+
+	. "gopkg.in/check.v1"
+)
+
+type SeasonCompositionSuite struct {
+	tmpdir string
+}
+
+var _ = Suite(&SeasonCompositionSuite{})
+
+func (s *SeasonCompositionSuite) SetUpTest(c *C) {
+	var err error
+	s.tmpdir, err = ioutil.TempDir("", "zeus-tests-composition")
+	c.Assert(err, IsNil)
+}
+
+func (s *SeasonCompositionSuite) TearDownTest(c *C) {
+	os.RemoveAll(s.tmpdir)
+}
+
+func (s *SeasonCompositionSuite) write(c *C, files map[string]string) {
+	for name, content := range files {
+		c.Assert(ioutil.WriteFile(filepath.Join(s.tmpdir, name), []byte(content), 0644), IsNil)
+	}
+}
+
+func (s *SeasonCompositionSuite) read(c *C, name string) (*SeasonFile, error) {
+	return ReadSeasonFile(filepath.Join(s.tmpdir, name), bytes.NewBuffer(nil))
+}
+
+func (s *SeasonCompositionSuite) TestInclude(c *C) {
+	s.write(c, map[string]string{
+		"base.fragment": `slack-user: "@lab"
+zones:
+  box:
+    minimal-temperature: 20
+    maximal-temperature: 30
+    states:
+      - name: day
+        temperature: 26
+`,
+		"box.season": `include: base.fragment
+zones:
+  box:
+    maximal-temperature: 32
+`,
+	})
+	season, err := s.read(c, "box.season")
+	c.Assert(err, IsNil)
+	c.Check(season.SlackUser, Equals, "@lab")
+	box := season.Zones["box"]
+	c.Check(box.MinimalTemperature, Equals, Temperature(20))
+	c.Check(box.MaximalTemperature, Equals, Temperature(32))
+	c.Assert(box.States, HasLen, 1)
+	c.Check(box.States[0].Temperature, Equals, Temperature(26))
+
+	s.write(c, map[string]string{
+		"a.fragment": "include: [b.fragment]\n",
+		"b.fragment": "include: a.fragment\n",
+	})
+	_, err = s.read(c, "a.fragment")
+	c.Check(err, ErrorMatches, "include cycle: .*a.fragment -> .*b.fragment -> .*a.fragment")
+}
+
+func (s *SeasonCompositionSuite) TestStateInheritance(c *C) {
+	s.write(c, map[string]string{
+		"box.season": `zones:
+  box:
+    states:
+      - name: cloudy-day
+        extends: day
+        visible-light: 20
+      - name: day
+        temperature: 26
+        humidity: 60
+        visible-light: 100
+      - name: wet-cloudy-day
+        extends: cloudy-day
+        humidity: 80
+`,
+	})
+	season, err := s.read(c, "box.season")
+	c.Assert(err, IsNil)
+	states := season.Zones["box"].States
+	c.Assert(states, HasLen, 3)
+	c.Check(states[0], Equals, State{
+		Name:         "cloudy-day",
+		Temperature:  26,
+		Humidity:     60,
+		Wind:         UndefinedWind,
+		VisibleLight: 20,
+		UVLight:      UndefinedLight,
+	})
+	c.Check(states[2].Name, Equals, "wet-cloudy-day")
+	c.Check(states[2].Temperature, Equals, Temperature(26))
+	c.Check(states[2].Humidity, Equals, Humidity(80))
+	c.Check(states[2].VisibleLight, Equals, Light(20))
+
+	errors := []struct {
+		Content, Error string
+	}{
+		{`zones:
+  box:
+    states:
+      - name: day
+        extends: night
+`, "zone 'box': state 'day' extends undefined state 'night'"},
+		{`zones:
+  box:
+    states:
+      - name: day
+        extends: night
+      - name: night
+        extends: day
+`, "zone 'box': state inheritance cycle: day -> night -> day"},
+	}
+	for _, d := range errors {
+		s.write(c, map[string]string{"error.season": d.Content})
+		_, err := s.read(c, "error.season")
+		c.Check(err, ErrorMatches, d.Error)
+	}
+}
+
+func (s *SeasonCompositionSuite) TestTemplates(c *C) {
+	s.write(c, map[string]string{
+		"templates.fragment": `templates:
+  day-night:
+    parameters:
+      night-temperature: 22
+    timezone: Europe/Paris
+    states:
+      - name: day
+        temperature: ${day-temperature}
+      - name: night
+        extends: day
+        temperature: ${night-temperature}
+    transitions:
+      - from: night
+        to: day
+        start: "${sunrise}"
+        duration: 30m
+      - from: day
+        to: night
+        start: "${sunset}"
+        duration: 30m
+`,
+		"boxes.season": `include: templates.fragment
+zones:
+  box:
+    template: day-night
+    parameters:
+      day-temperature: 26
+      sunrise: "06:00"
+      sunset: "18:00"
+  tunnel:
+    template: day-night
+    parameters:
+      day-temperature: 24
+      night-temperature: 20
+      sunrise: "07:00"
+      sunset: "19:00"
+    minimal-temperature: 18
+`,
+	})
+	season, err := s.read(c, "boxes.season")
+	c.Assert(err, IsNil)
+	c.Assert(season.Zones, HasLen, 2)
+	box, tunnel := season.Zones["box"], season.Zones["tunnel"]
+	c.Check(box.Timezone, Equals, "Europe/Paris")
+	c.Assert(box.States, HasLen, 2)
+	c.Check(box.States[0].Temperature, Equals, Temperature(26))
+	c.Check(box.States[1].Temperature, Equals, Temperature(22))
+	c.Assert(box.Transitions, HasLen, 2)
+	c.Check(box.Transitions[0].Start.Format("15:04"), Equals, "06:00")
+	c.Check(tunnel.MinimalTemperature, Equals, Temperature(18))
+	c.Check(tunnel.States[0].Temperature, Equals, Temperature(24))
+	c.Check(tunnel.States[1].Temperature, Equals, Temperature(20))
+	c.Check(tunnel.Transitions[1].Start.Format("15:04"), Equals, "19:00")
+
+	errors := []struct {
+		Content, Error string
+	}{
+		{`zones:
+  box:
+    template: foo
+`, "zone 'box': undefined template 'foo'"},
+		{`templates:
+  foo:
+    states:
+      - name: day
+        temperature: ${temperature}
+zones:
+  box:
+    template: foo
+`, "zone 'box': template 'foo': undefined template parameter 'temperature'"},
+		{`zones:
+  box:
+    parameters:
+      foo: 1
+`, "zone 'box': parameters require a template"},
+	}
+	for _, d := range errors {
+		s.write(c, map[string]string{"error.season": d.Content})
+		_, err := s.read(c, "error.season")
+		c.Check(err, ErrorMatches, d.Error)
+	}
+}
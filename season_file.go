@@ -10,8 +10,11 @@ import (
 )
 
 type SeasonFile struct {
-	SlackUser string `yaml:"slack-user"`
-	Zones     map[string]ZoneClimate
+	// FormatVersion is the version of the season file format, see
+	// MigrateSeason.
+	FormatVersion int    `yaml:"format-version,omitempty"`
+	SlackUser     string `yaml:"slack-user"`
+	Zones         map[string]ZoneClimate
 }
 
 type deprecatedLine struct {
@@ -19,50 +22,13 @@ type deprecatedLine struct {
 	isError       bool
 }
 
-func checkDeprecatedLinesInZone(name string, items yaml.MapSlice) []deprecatedLine {
-	var res []deprecatedLine = nil
-	prefix := "zones." + name + "."
-	for _, item := range items {
-		switch item.Key.(string) {
-		case "can-interface":
-			res = append(res, deprecatedLine{name: prefix + "can-interface", comment: "value ignored", isError: false})
-		case "devices-id":
-			res = append(res, deprecatedLine{name: prefix + "devices-id", comment: "value ignored", isError: false})
-		case "climate-report-file":
-			res = append(res, deprecatedLine{
-				name:    prefix + "climate-report-file",
-				comment: "climate logs are saved under `/data/fort-user/fort-experiments/climate/" + name + ".<timestamp>.climate.txt`",
-				isError: false,
-			})
-		}
-	}
-
-	return res
-}
-
 func checkDeprecatedLines(data []byte) ([]deprecatedLine, error) {
 	parsed := yaml.MapSlice{}
 	err := yaml.Unmarshal(data, &parsed)
 	if err != nil {
 		return nil, err
 	}
-	var res []deprecatedLine = nil
-
-	for _, item := range parsed {
-		key := item.Key.(string)
-		if key == "emails" {
-			res = append(res, deprecatedLine{name: key, comment: "value ignored", isError: false})
-			continue
-		}
-		if key == "zones" {
-			for _, zoneItem := range item.Value.(yaml.MapSlice) {
-				zoneName := zoneItem.Key.(string)
-				res = append(res, checkDeprecatedLinesInZone(zoneName, zoneItem.Value.(yaml.MapSlice))...)
-			}
-		}
-	}
-
-	return res, nil
+	return pendingMigrations(parsed)
 }
 
 func formatDeprecatedLines(lines []deprecatedLine, writer io.Writer) error {
@@ -90,14 +56,22 @@ func formatDeprecatedLines(lines []deprecatedLine, writer io.Writer) error {
 	return nil
 }
 
+// ReadSeasonFile reads and expands a season file. Deprecation
+// warnings are written to writer.
 func ReadSeasonFile(filename string, writer io.Writer) (*SeasonFile, error) {
-	data, err := ioutil.ReadFile(filename)
+	data, err := expandSeasonFile(filename)
 	if err != nil {
 		return nil, err
 	}
+	return parseSeasonFile(data, writer)
+}
+
+// parseSeasonFile parses the expanded content of a season file, and
+// writes the deprecation warnings to writer.
+func parseSeasonFile(data []byte, writer io.Writer) (*SeasonFile, error) {
 	s := &SeasonFile{}
 
-	err = yaml.Unmarshal(data, s)
+	err := yaml.Unmarshal(data, s)
 	if err != nil {
 		return nil, err
 	}
@@ -0,0 +1,274 @@
+package zeus
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// SeasonIssue is a problem found in a season file by LintSeasonFile.
+type SeasonIssue struct {
+	Filename string
+	// Line is the line of the issue in the file, or 0 if unknown.
+	Line int
+	// Error is true if the season file would not behave as
+	// written. Otherwise the issue is a warning.
+	Error   bool
+	Message string
+}
+
+func (i SeasonIssue) String() string {
+	severity := "warning"
+	if i.Error == true {
+		severity = "error"
+	}
+	if i.Line == 0 {
+		return fmt.Sprintf("%s: %s: %s", i.Filename, severity, i.Message)
+	}
+	return fmt.Sprintf("%s:%d: %s: %s", i.Filename, i.Line, severity, i.Message)
+}
+
+type seasonLinter struct {
+	document *seasonDocument
+	issues   []SeasonIssue
+}
+
+func (l *seasonLinter) report(path string, isError bool, format string, args ...interface{}) {
+	filename, line := l.document.position(path)
+	l.issues = append(l.issues, SeasonIssue{
+		Filename: filename,
+		Line:     line,
+		Error:    isError,
+		Message:  fmt.Sprintf(format, args...),
+	})
+}
+
+var yamlErrorLineRx = regexp.MustCompile(`line ([0-9]+)`)
+var deprecatedNameRx = regexp.MustCompile(`^WARNING: '([^']+)'`)
+
+// LintSeasonFile reads a season file and reports all problems found
+// in it, for an experiment started at reference. Only I/O errors are
+// returned as error, invalid files are reported as SeasonIssue.
+func LintSeasonFile(filename string, reference time.Time) ([]SeasonIssue, error) {
+	if _, err := ioutil.ReadFile(filename); err != nil {
+		return nil, err
+	}
+	document, data, err := readExpandedSeason(filename)
+	var season *SeasonFile
+	warnings := bytes.NewBuffer(nil)
+	if err == nil {
+		season, err = parseSeasonFile(data, warnings)
+	}
+	if err != nil {
+		issue := SeasonIssue{Filename: filename, Error: true, Message: err.Error()}
+		if m := yamlErrorLineRx.FindStringSubmatch(err.Error()); m != nil {
+			issue.Line, _ = strconv.Atoi(m[1])
+		}
+		// lines are the ones of the file with the error, or of the
+		// expanded content.
+		var fileErr seasonFileError
+		if errors.As(err, &fileErr) == true {
+			issue.Filename = fileErr.filename
+		} else if document != nil && issue.Line > 0 {
+			issue.Filename, issue.Line = document.expandedPosition(data, issue.Line)
+		}
+		return []SeasonIssue{issue}, nil
+	}
+	l := &seasonLinter{document: document}
+	for _, warning := range strings.Split(strings.TrimSpace(warnings.String()), "\n") {
+		if len(warning) == 0 {
+			continue
+		}
+		path := ""
+		if m := deprecatedNameRx.FindStringSubmatch(warning); m != nil {
+			path = m[1]
+		}
+		l.report(path, false, "%s", strings.TrimPrefix(warning, "WARNING: "))
+	}
+
+	names := make([]string, 0, len(season.Zones))
+	for name := range season.Zones {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	zones, err := season.ResolveZones()
+	if err != nil {
+		l.report("zones", true, "%s", err)
+		zones = nil
+	}
+	for _, name := range names {
+		if season.Zones[name].follows() == false {
+			l.lintZone(name, season.Zones[name], reference)
+		} else if resolved, ok := zones[name]; ok == true {
+			// the followed schedule is linted with its zone, only
+			// the offset values are checked.
+			l.lintStates("zones."+name, resolved)
+		}
+	}
+
+	sort.SliceStable(l.issues, func(i, j int) bool {
+		if l.issues[i].Filename != l.issues[j].Filename {
+			return l.issues[i].Filename < l.issues[j].Filename
+		}
+		return l.issues[i].Line < l.issues[j].Line
+	})
+	return l.issues, nil
+}
+
+// lintedValue is a state field value, at a given path in the file.
+type lintedValue struct {
+	path  string
+	day   int
+	value BoundedUnit
+}
+
+func stateValues(prefix string, s State) map[string][]lintedValue {
+	res := map[string][]lintedValue{}
+	add := func(name string, value BoundedUnit, table *DriftTable, unit func(float64) BoundedUnit) {
+		path := prefix + "." + name
+		if table == nil {
+			if IsUndefined(value) == false {
+				res[name] = append(res[name], lintedValue{path: path, value: value})
+			}
+			return
+		}
+		for i, p := range table.Points {
+			res[name] = append(res[name], lintedValue{
+				path:  fmt.Sprintf("%s.table[%d]", path, i),
+				day:   p.Day,
+				value: unit(p.Value),
+			})
+		}
+	}
+	for _, c := range channels {
+		add(c.Name, c.Value(s), c.Drift(s.Drift), c.Unit)
+	}
+	if s.HumidityTarget != nil {
+		// checks the relative humidity achieved at the state temperature
+		key := "vpd"
+		if s.HumidityTarget.AbsoluteHumidity > 0 {
+			key = "absolute-humidity"
+		}
+		res["humidity"] = append(res["humidity"], lintedValue{path: prefix + "." + key, value: s.EffectiveHumidity()})
+	}
+	return res
+}
+
+func (v lintedValue) describe(name string) string {
+	if v.day == 0 {
+		return fmt.Sprintf("%s %g", name, v.value.Value())
+	}
+	return fmt.Sprintf("%s %g on day %d", name, v.value.Value(), v.day)
+}
+
+func (l *seasonLinter) lintStates(zonePath string, zone ZoneClimate) {
+	for i, s := range zone.States {
+		values := stateValues(fmt.Sprintf("%s.states[%d]", zonePath, i), s)
+		for _, channel := range channels {
+			name := channel.Name
+			for _, v := range values[name] {
+				if v.value.Value() > v.value.MaxValue() || v.value.Value() < v.value.MinValue() {
+					l.report(v.path, false, "state '%s': %s is outside of the default %s range [%g,%g], the zone hardware limits should allow it",
+						s.Name, v.describe(name), name, v.value.MinValue(), v.value.MaxValue())
+				}
+			}
+		}
+		bounds := []struct {
+			name     string
+			min, max BoundedUnit
+		}{
+			{"temperature", zone.MinimalTemperature, zone.MaximalTemperature},
+			{"humidity", zone.MinimalHumidity, zone.MaximalHumidity},
+		}
+		for _, b := range bounds {
+			for _, v := range values[b.name] {
+				target := v.value.Value()
+				// bounds left to zero are not set in the season file
+				if IsUndefined(b.min) == false && b.min.Value() != 0.0 && target < b.min.Value() {
+					l.report(v.path, true, "state '%s': %s is below minimal-%s %g, alarm will be raised", s.Name, v.describe(b.name), b.name, b.min.Value())
+				}
+				if IsUndefined(b.max) == false && b.max.Value() != 0.0 && target > b.max.Value() {
+					l.report(v.path, true, "state '%s': %s is above maximal-%s %g, alarm will be raised", s.Name, v.describe(b.name), b.name, b.max.Value())
+				}
+			}
+		}
+	}
+}
+
+func transitionIndex(zone ZoneClimate, tr Transition) int {
+	for i, t := range zone.Transitions {
+		if t == tr {
+			return i
+		}
+	}
+	return -1
+}
+
+func (l *seasonLinter) lintReachability(zonePath string, zone ZoneClimate, i *climateInterpolation) {
+	if len(zone.States) == 0 {
+		return
+	}
+	reached := map[string]bool{zone.States[0].Name: true}
+	// the experiment end moves to its safe state, but no
+	// transition is run afterwards.
+	if zone.End != nil && len(zone.End.State) > 0 {
+		reached[zone.End.State] = true
+	}
+	toVisit := []string{zone.States[0].Name}
+	for len(toVisit) > 0 {
+		current := i.states[toVisit[0]]
+		toVisit = toVisit[1:]
+		for _, tr := range current.transitionForward {
+			if reached[tr.To] == false {
+				reached[tr.To] = true
+				toVisit = append(toVisit, tr.To)
+			}
+		}
+	}
+	for idx, s := range zone.States {
+		if reached[s.Name] == false {
+			l.report(fmt.Sprintf("%s.states[%d]", zonePath, idx), false, "state '%s' is never reached from initial state '%s'", s.Name, zone.States[0].Name)
+		}
+	}
+}
+
+// lintCollisions reports transitions from the same state occuring at
+// the same time with the same priority.
+func (l *seasonLinter) lintCollisions(zonePath string, zone ZoneClimate, i *climateInterpolation) {
+	for _, c := range i.collisions(zone.States) {
+		last := c.transitions[len(c.transitions)-1]
+		l.report(fmt.Sprintf("%s.transitions[%d]", zonePath, transitionIndex(zone, last)), true, "%s", c)
+	}
+}
+
+// hasState returns true if zone defines the state name.
+func hasState(zone ZoneClimate, name string) bool {
+	for _, s := range zone.States {
+		if s.Name == name {
+			return true
+		}
+	}
+	return false
+}
+
+func (l *seasonLinter) lintZone(name string, zone ZoneClimate, reference time.Time) {
+	zonePath := "zones." + name
+	l.lintStates(zonePath, zone)
+	if zone.End != nil && len(zone.End.State) > 0 && hasState(zone, zone.End.State) == false {
+		l.report(zonePath+".end.state", true, "experiment end state '%s' is not defined", zone.End.State)
+		return
+	}
+	i, err := newClimateInterpolation(zone, reference)
+	if err != nil {
+		l.report(zonePath, true, "zone '%s': %s", name, err)
+		return
+	}
+	l.lintReachability(zonePath, zone, i)
+	l.lintCollisions(zonePath, zone, i)
+}
@@ -0,0 +1,238 @@
+package zeus
+
+import (
+	"fmt"
+	"io/ioutil"
+	"path/filepath"
+	"time"
+
+	. "gopkg.in/check.v1"
+)
+
+type SeasonLintSuite struct{}
+
+var _ = Suite(&SeasonLintSuite{})
+
+func (s *SeasonLintSuite) TestYAMLPositions(c *C) {
+	positions := parseYAMLPositions([]byte(`# a comment
+slack-user: "@foo # bar"
+zones:
+  box:
+    states:
+    - name: day
+      temperature:
+        table:
+          - {day: 1, value: 3}
+          - day: 2
+            value: 4
+    - name: night
+
+    transitions:
+      - from: night # comment: with colon
+        to: day
+  tunnel:
+    minimal-temperature: 20
+`))
+	c.Check(positions, DeepEquals, yamlPositions{
+		"slack-user":                                     2,
+		"zones":                                          3,
+		"zones.box":                                      4,
+		"zones.box.states":                               5,
+		"zones.box.states[0]":                            6,
+		"zones.box.states[0].name":                       6,
+		"zones.box.states[0].temperature":                7,
+		"zones.box.states[0].temperature.table":          8,
+		"zones.box.states[0].temperature.table[0]":       9,
+		"zones.box.states[0].temperature.table[1]":       10,
+		"zones.box.states[0].temperature.table[1].day":   10,
+		"zones.box.states[0].temperature.table[1].value": 11,
+		"zones.box.states[1]":                            12,
+		"zones.box.states[1].name":                       12,
+		"zones.box.transitions":                          14,
+		"zones.box.transitions[0]":                       15,
+		"zones.box.transitions[0].from":                  15,
+		"zones.box.transitions[0].to":                    16,
+		"zones.tunnel":                                   17,
+		"zones.tunnel.minimal-temperature":               18,
+	})
+	c.Check(positions.Line("zones.box.transitions[0].start"), Equals, 15)
+	c.Check(positions.Line("zones.foo.states"), Equals, 3)
+	c.Check(positions.Line("foo"), Equals, 0)
+}
+
+func (s *SeasonLintSuite) TestLint(c *C) {
+	filename := filepath.Join(c.MkDir(), "bad.season")
+	err := ioutil.WriteFile(filename, []byte(`zones:
+  box:
+    minimal-temperature: 20.0
+    maximal-temperature: 31.0
+    maximal-humidity: 80
+    states:
+      - name: day
+        temperature: 45
+        humidity: 60
+      - name: night
+        temperature: 22.0
+        humidity:
+          table:
+            - {day: 1, value: 60}
+            - {day: 30, value: 82}
+      - name: lost
+        temperature: 24
+    transitions:
+      - from: night
+        to: day
+        start: 06:00
+      - from: day
+        to: night
+        start: 18:00
+      - from: day
+        to: night
+        start: 18:00
+        recurrence:
+          every: 2
+      - from: day
+        to: night
+        start: 18:00
+        recurrence:
+          weekdays: [mon]
+`), 0644)
+	c.Assert(err, IsNil)
+
+	issues, err := LintSeasonFile(filename, time.Date(2021, 3, 1, 0, 0, 0, 0, time.UTC))
+	c.Assert(err, IsNil)
+	expected := []SeasonIssue{
+		{Line: 8, Error: false, Message: "state 'day': temperature 45 is outside of the default temperature range [15,40], the zone hardware limits should allow it"},
+		{Line: 8, Error: true, Message: "state 'day': temperature 45 is above maximal-temperature 31, alarm will be raised"},
+		{Line: 15, Error: true, Message: "state 'night': humidity 82 on day 30 is above maximal-humidity 80, alarm will be raised"},
+		{Line: 16, Error: false, Message: "state 'lost' is never reached from initial state 'day'"},
+		{Line: 30, Error: true, Message: "RecurringTransition{From: day, To: night, Start: 18:00, Duration: 0s, Recurrence: every 2 days} and RecurringTransition{From: day, To: night, Start: 18:00, Duration: 0s, Recurrence: on mon} both start from 'day' at 18:00 on day 1 with the same priority: set a different priority to choose which one occurs"},
+	}
+	c.Assert(len(issues), Equals, len(expected), Commentf("%v", issues))
+	for i, e := range expected {
+		e.Filename = filename
+		c.Check(issues[i], Equals, e)
+	}
+	c.Check(issues[0].String(), Equals, filename+":8: warning: state 'day': temperature 45 is outside of the default temperature range [15,40], the zone hardware limits should allow it")
+
+	err = ioutil.WriteFile(filename, []byte(`zones:
+  box:
+    states:
+      - name: day
+        temperature: foo
+`), 0644)
+	c.Assert(err, IsNil)
+	issues, err = LintSeasonFile(filename, time.Now())
+	c.Assert(err, IsNil)
+	c.Assert(len(issues), Equals, 1)
+	c.Check(issues[0].Line, Equals, 5)
+	c.Check(issues[0].Error, Equals, true)
+}
+
+func (s *SeasonLintSuite) TestLintEndState(c *C) {
+	filename := filepath.Join(c.MkDir(), "end.season")
+	season := `zones:
+  box:
+    states:
+      - name: day
+        temperature: 26
+      - name: night
+        temperature: 22
+      - name: safe
+        temperature: 20
+    transitions:
+      - from: day
+        to: night
+        start: 18:00
+      - from: night
+        to: day
+        start: 06:00
+    end:
+      day: 30
+      state: %s
+`
+	c.Assert(ioutil.WriteFile(filename, []byte(fmt.Sprintf(season, "safe")), 0644), IsNil)
+	issues, err := LintSeasonFile(filename, time.Date(2021, 3, 1, 0, 0, 0, 0, time.UTC))
+	c.Assert(err, IsNil)
+	c.Check(issues, HasLen, 0)
+
+	c.Assert(ioutil.WriteFile(filename, []byte(fmt.Sprintf(season, "unsafe")), 0644), IsNil)
+	issues, err = LintSeasonFile(filename, time.Date(2021, 3, 1, 0, 0, 0, 0, time.UTC))
+	c.Assert(err, IsNil)
+	c.Check(issues, DeepEquals, []SeasonIssue{
+		{Filename: filename, Line: 19, Error: true, Message: "experiment end state 'unsafe' is not defined"},
+	})
+}
+
+func (s *SeasonLintSuite) TestLintComposedFile(c *C) {
+	dir := c.MkDir()
+	common := filepath.Join(dir, "common.season")
+	filename := filepath.Join(dir, "main.season")
+	c.Assert(ioutil.WriteFile(common, []byte(`templates:
+  box:
+    parameters:
+      day: 26
+    maximal-temperature: 30
+    states:
+      - name: day
+        temperature: ${day}
+        humidity: 50
+      - name: hot
+        extends: day
+        temperature: 32
+`), 0644), IsNil)
+	c.Assert(ioutil.WriteFile(filename, []byte(`include: common.season
+zones:
+  foraging:
+    maximal-humidity: 70
+    states:
+      - name: day
+        temperature: 24
+        humidity: 80
+      - name: night
+        extends: day
+        temperature: 20
+  nest:
+    template: box
+    parameters:
+      day: 31
+`), 0644), IsNil)
+
+	issues, err := LintSeasonFile(filename, time.Date(2021, 3, 1, 0, 0, 0, 0, time.UTC))
+	c.Assert(err, IsNil)
+	expected := []SeasonIssue{
+		{Filename: common, Line: 8, Error: true, Message: "state 'day': temperature 31 is above maximal-temperature 30, alarm will be raised"},
+		{Filename: common, Line: 10, Error: false, Message: "state 'hot' is never reached from initial state 'day'"},
+		{Filename: common, Line: 12, Error: true, Message: "state 'hot': temperature 32 is above maximal-temperature 30, alarm will be raised"},
+		// inherited from the extended state
+		{Filename: filename, Line: 8, Error: true, Message: "state 'day': humidity 80 is above maximal-humidity 70, alarm will be raised"},
+		{Filename: filename, Line: 8, Error: true, Message: "state 'night': humidity 80 is above maximal-humidity 70, alarm will be raised"},
+		{Filename: filename, Line: 9, Error: false, Message: "state 'night' is never reached from initial state 'day'"},
+	}
+	c.Assert(len(issues), Equals, len(expected), Commentf("%v", issues))
+	for i, e := range expected {
+		c.Check(issues[i], Equals, e)
+	}
+
+	// errors in the expanded content are reported in their file
+	c.Assert(ioutil.WriteFile(common, []byte(`templates:
+  box:
+    states:
+      - name: day
+        temperature: foo
+`), 0644), IsNil)
+	issues, err = LintSeasonFile(filename, time.Now())
+	c.Assert(err, IsNil)
+	c.Assert(len(issues), Equals, 1)
+	c.Check(issues[0].Filename, Equals, common)
+	c.Check(issues[0].Line, Equals, 5)
+
+	c.Assert(ioutil.WriteFile(common, []byte(`templates:
+  box: [
+`), 0644), IsNil)
+	issues, err = LintSeasonFile(filename, time.Now())
+	c.Assert(err, IsNil)
+	c.Assert(len(issues), Equals, 1)
+	c.Check(issues[0].Filename, Equals, common)
+	c.Check(issues[0].Line, Equals, 2)
+}
@@ -0,0 +1,188 @@
+package zeus
+
+import (
+	"bytes"
+	"fmt"
+	"strings"
+
+	"gopkg.in/yaml.v2"
+)
+
+// SeasonFormatVersion is the current version of the season file
+// format. Files without a format-version are at version 0.
+const SeasonFormatVersion = 1
+
+// deprecatedKey is a key removed by a seasonMigration.
+type deprecatedKey struct {
+	// path of the key, where '*' matches any key, like
+	// "zones.*.devices-id".
+	path string
+	// comment explains the deprecation. It receives the keys matched
+	// by '*'.
+	comment func(matched []string) string
+	isError bool
+}
+
+// seasonMigration upgrades season files to a given format version.
+type seasonMigration struct {
+	Version int
+	removed []deprecatedKey
+}
+
+func valueIgnored([]string) string { return "value ignored" }
+
+var seasonMigrations = []seasonMigration{
+	{
+		Version: 1,
+		removed: []deprecatedKey{
+			{path: "emails", comment: valueIgnored},
+			{path: "zones.*.can-interface", comment: valueIgnored},
+			{path: "zones.*.devices-id", comment: valueIgnored},
+			{
+				path: "zones.*.climate-report-file",
+				comment: func(matched []string) string {
+					return "climate logs are saved under `/data/fort-user/fort-experiments/climate/" + matched[0] + ".<timestamp>.climate.txt`"
+				},
+			},
+		},
+	},
+}
+
+func matchYAMLKeys(value interface{}, pattern []string, path string, matched []string, found func(path string, matched []string)) {
+	m, ok := value.(yaml.MapSlice)
+	if ok == false {
+		return
+	}
+	for _, item := range m {
+		key := yamlKey(item)
+		if pattern[0] != "*" && pattern[0] != key {
+			continue
+		}
+		keyMatched := matched
+		if pattern[0] == "*" {
+			keyMatched = append(append([]string{}, matched...), key)
+		}
+		keyPath := joinYAMLPath(path, key)
+		if len(pattern) == 1 {
+			found(keyPath, keyMatched)
+		} else {
+			matchYAMLKeys(item.Value, pattern[1:], keyPath, keyMatched, found)
+		}
+	}
+}
+
+func seasonFormatVersion(content yaml.MapSlice) (int, error) {
+	value, ok := yamlLookup(content, "format-version")
+	if ok == false {
+		return 0, nil
+	}
+	version, ok := value.(int)
+	if ok == false || version < 0 {
+		return 0, fmt.Errorf("invalid format-version '%v': should be a positive integer", value)
+	}
+	if version > SeasonFormatVersion {
+		return 0, fmt.Errorf("season file format-version %d is not supported (latest is %d), please update zeus-cli", version, SeasonFormatVersion)
+	}
+	return version, nil
+}
+
+// pendingMigrations returns the keys of content removed by the
+// migrations it still requires.
+func pendingMigrations(content yaml.MapSlice) ([]deprecatedLine, error) {
+	version, err := seasonFormatVersion(content)
+	if err != nil {
+		return nil, err
+	}
+	var res []deprecatedLine = nil
+	for _, m := range seasonMigrations {
+		if m.Version <= version {
+			continue
+		}
+		for _, key := range m.removed {
+			matchYAMLKeys(content, strings.Split(key.path, "."), "", nil, func(path string, matched []string) {
+				res = append(res, deprecatedLine{name: path, comment: key.comment(matched), isError: key.isError})
+			})
+		}
+	}
+	return res, nil
+}
+
+func yamlIndent(line string) (int, string) {
+	content := strings.TrimLeft(line, " ")
+	return len(line) - len(content), content
+}
+
+// MigrateSeason rewrites the content of a season file to the current
+// format version. Deprecated keys are removed, all other lines and
+// comments are kept. It also returns the removed keys.
+func MigrateSeason(data []byte) ([]byte, []string, error) {
+	content := yaml.MapSlice{}
+	if err := yaml.Unmarshal(data, &content); err != nil {
+		return nil, nil, err
+	}
+	deprecated, err := pendingMigrations(content)
+	if err != nil {
+		return nil, nil, err
+	}
+	version, _ := seasonFormatVersion(content)
+	if version == SeasonFormatVersion {
+		return data, nil, nil
+	}
+
+	positions := parseYAMLPositions(data)
+	lines := strings.SplitAfter(string(data), "\n")
+	removed := make([]bool, len(lines))
+	var removedKeys []string
+	for _, d := range deprecated {
+		start, ok := positions[d.name]
+		if ok == false {
+			return nil, nil, fmt.Errorf("cannot locate '%s': only block style YAML can be migrated", d.name)
+		}
+		removedKeys = append(removedKeys, d.name)
+		start--
+		keyIndent, _ := yamlIndent(lines[start])
+		removed[start] = true
+		last := start
+		for i := start + 1; i < len(lines); i++ {
+			indent, rest := yamlIndent(strings.TrimRight(lines[i], "\r\n"))
+			if len(rest) == 0 {
+				continue
+			}
+			isItem := rest == "-" || strings.HasPrefix(rest, "- ")
+			if indent < keyIndent || (indent == keyIndent && isItem == false) {
+				break
+			}
+			last = i
+		}
+		for i := start; i <= last; i++ {
+			removed[i] = true
+		}
+	}
+
+	res := bytes.NewBuffer(nil)
+	versionLine := fmt.Sprintf("format-version: %d\n", SeasonFormatVersion)
+	versionWritten := false
+	if line, ok := positions["format-version"]; ok == true {
+		lines[line-1] = versionLine
+		versionWritten = true
+	}
+	for i, line := range lines {
+		if removed[i] == true {
+			continue
+		}
+		_, rest := yamlIndent(line)
+		rest = strings.TrimSpace(rest)
+		if versionWritten == false && len(rest) > 0 && strings.HasPrefix(rest, "#") == false && rest != "---" {
+			res.WriteString(versionLine)
+			versionWritten = true
+		}
+		res.WriteString(line)
+	}
+	if versionWritten == false {
+		if res.Len() > 0 && bytes.HasSuffix(res.Bytes(), []byte("\n")) == false {
+			res.WriteString("\n")
+		}
+		res.WriteString(versionLine)
+	}
+	return res.Bytes(), removedKeys, nil
+}
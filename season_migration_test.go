@@ -0,0 +1,70 @@
+package zeus
+
+import (
+	. "gopkg.in/check.v1"
+)
+
+type SeasonMigrationSuite struct{}
+
+var _ = Suite(&SeasonMigrationSuite{})
+
+func (s *SeasonMigrationSuite) TestMigrate(c *C) {
+	input := `# a legacy season file
+emails:
+  - noreply@unil.ch
+  - other@unil.ch
+slack-user: "@John Doe" # who to notify
+zones:
+  box:
+    # hardware is now set in the node configuration
+    can-interface: slcan0
+    devices-id: 1
+    climate-report-file: /foo/bar
+    states:
+      - name: day # comments are kept
+        temperature: 26
+`
+	expected := `# a legacy season file
+format-version: 1
+slack-user: "@John Doe" # who to notify
+zones:
+  box:
+    # hardware is now set in the node configuration
+    states:
+      - name: day # comments are kept
+        temperature: 26
+`
+	output, removed, err := MigrateSeason([]byte(input))
+	c.Assert(err, IsNil)
+	c.Check(string(output), Equals, expected)
+	c.Check(removed, DeepEquals, []string{"emails", "zones.box.can-interface", "zones.box.devices-id", "zones.box.climate-report-file"})
+
+	// migrated files are left untouched
+	again, removed, err := MigrateSeason(output)
+	c.Assert(err, IsNil)
+	c.Check(string(again), Equals, expected)
+	c.Check(removed, HasLen, 0)
+
+	output, _, err = MigrateSeason([]byte("format-version: 0\nemails: [noreply@unil.ch]\n"))
+	c.Check(err, IsNil)
+	c.Check(string(output), Equals, "format-version: 1\n")
+	_, _, err = MigrateSeason([]byte("{emails: [noreply@unil.ch]}\n"))
+	c.Check(err, ErrorMatches, "cannot locate 'emails': only block style YAML can be migrated")
+	output, removed, err = MigrateSeason([]byte("format-version: 0 # legacy\nemails:\n- noreply@unil.ch\nzones: {}\n"))
+	c.Assert(err, IsNil)
+	c.Check(string(output), Equals, "format-version: 1\nzones: {}\n")
+
+	_, _, err = MigrateSeason([]byte("format-version: 2\n"))
+	c.Check(err, ErrorMatches, "season file format-version 2 is not supported \\(latest is 1\\), please update zeus-cli")
+	_, _, err = MigrateSeason([]byte("format-version: foo\n"))
+	c.Check(err, ErrorMatches, "invalid format-version 'foo': should be a positive integer")
+}
+
+func (s *SeasonMigrationSuite) TestVersionedDeprecations(c *C) {
+	lines, err := checkDeprecatedLines([]byte("format-version: 1\nemails: [noreply@unil.ch]\n"))
+	c.Check(err, IsNil)
+	c.Check(lines, HasLen, 0)
+	lines, err = checkDeprecatedLines([]byte("emails: [noreply@unil.ch]\n"))
+	c.Check(err, IsNil)
+	c.Check(lines, HasLen, 1)
+}
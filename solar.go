@@ -0,0 +1,127 @@
+package zeus
+
+import (
+	"fmt"
+	"math"
+	"regexp"
+	"time"
+)
+
+// SolarEvent anchors a Transition start time to the sun position
+// instead of a fixed time of the day.
+type SolarEvent int
+
+const (
+	NoSolarEvent SolarEvent = iota
+	Sunrise
+	Sunset
+)
+
+func (e SolarEvent) String() string {
+	switch e {
+	case Sunrise:
+		return "sunrise"
+	case Sunset:
+		return "sunset"
+	default:
+		return ""
+	}
+}
+
+var solarStartRx = regexp.MustCompile(`\A(sunrise|sunset)(?:([+-])(.+))?\z`)
+
+// parseSolarStart parses start times like "sunrise", "sunset-30m" or
+// "sunrise+1h15m". It returns NoSolarEvent if s is not a solar start.
+func parseSolarStart(s string) (SolarEvent, time.Duration, error) {
+	m := solarStartRx.FindStringSubmatch(s)
+	if m == nil {
+		return NoSolarEvent, 0, nil
+	}
+	event := Sunrise
+	if m[1] == "sunset" {
+		event = Sunset
+	}
+	if len(m[2]) == 0 {
+		return event, 0, nil
+	}
+	offset, err := time.ParseDuration(m[2] + m[3])
+	if err != nil {
+		return NoSolarEvent, 0, fmt.Errorf("invalid %s offset '%s': %s", m[1], m[2]+m[3], err)
+	}
+	return event, offset, nil
+}
+
+func formatSolarStart(event SolarEvent, offset time.Duration) string {
+	if offset == 0 {
+		return event.String()
+	}
+	if offset < 0 {
+		return fmt.Sprintf("%s-%s", event, -offset)
+	}
+	return fmt.Sprintf("%s+%s", event, offset)
+}
+
+const (
+	solarZenith = 90.833
+	degToRad    = math.Pi / 180.0
+	radToDeg    = 180.0 / math.Pi
+)
+
+func normalizeAngle(v, max float64) float64 {
+	v = math.Mod(v, max)
+	if v < 0 {
+		v += max
+	}
+	return v
+}
+
+// SolarEventTime computes the time of sunrise or sunset on the given
+// day for a site at latitude and longitude (in degrees, north and east
+// positive). The returned event happens on the local solar day of
+// the given date. It returns false if the event does not occur that
+// day, i.e. during polar days or nights. The computation is the one
+// of the Almanac for Computers, and is precise to a couple of
+// minutes.
+func SolarEventTime(event SolarEvent, year int, month time.Month, day int, latitude, longitude float64) (time.Time, bool) {
+	if event != Sunrise && event != Sunset {
+		return time.Time{}, false
+	}
+	date := time.Date(year, month, day, 0, 0, 0, 0, time.UTC)
+	dayOfYear := float64(date.YearDay())
+	lngHour := longitude / 15.0
+
+	approx := dayOfYear + (18.0-lngHour)/24.0
+	if event == Sunrise {
+		approx = dayOfYear + (6.0-lngHour)/24.0
+	}
+
+	meanAnomaly := 0.9856*approx - 3.289
+	trueLongitude := normalizeAngle(meanAnomaly+
+		1.916*math.Sin(meanAnomaly*degToRad)+
+		0.020*math.Sin(2*meanAnomaly*degToRad)+
+		282.634, 360.0)
+
+	rightAscension := normalizeAngle(math.Atan(0.91764*math.Tan(trueLongitude*degToRad))*radToDeg, 360.0)
+	rightAscension += math.Floor(trueLongitude/90.0)*90.0 - math.Floor(rightAscension/90.0)*90.0
+	rightAscension /= 15.0
+
+	sinDeclination := 0.39782 * math.Sin(trueLongitude*degToRad)
+	cosDeclination := math.Cos(math.Asin(sinDeclination))
+
+	cosHourAngle := (math.Cos(solarZenith*degToRad) - sinDeclination*math.Sin(latitude*degToRad)) /
+		(cosDeclination * math.Cos(latitude*degToRad))
+	if cosHourAngle > 1.0 || cosHourAngle < -1.0 {
+		return time.Time{}, false
+	}
+
+	hourAngle := math.Acos(cosHourAngle) * radToDeg
+	if event == Sunrise {
+		hourAngle = 360.0 - hourAngle
+	}
+	hourAngle /= 15.0
+
+	localMeanTime := normalizeAngle(hourAngle+rightAscension-0.06571*approx-6.622, 24.0)
+	universalTime := localMeanTime - lngHour
+
+	return date.Add(time.Duration(universalTime * float64(time.Hour))).Truncate(time.Second), true
+}
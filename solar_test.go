@@ -0,0 +1,98 @@
+package zeus
+
+import (
+	"time"
+
+	. "gopkg.in/check.v1"
+)
+
+type SolarSuite struct{}
+
+var _ = Suite(&SolarSuite{})
+
+func (s *SolarSuite) TestParsing(c *C) {
+	testdata := []struct {
+		Text   string
+		Event  SolarEvent
+		Offset time.Duration
+		Format string
+	}{
+		{"12:00", NoSolarEvent, 0, ""},
+		{"sunrise", Sunrise, 0, "sunrise"},
+		{"sunset", Sunset, 0, "sunset"},
+		{"sunrise+30m", Sunrise, 30 * time.Minute, "sunrise+30m0s"},
+		{"sunset-1h15m", Sunset, -75 * time.Minute, "sunset-1h15m0s"},
+	}
+	for _, d := range testdata {
+		event, offset, err := parseSolarStart(d.Text)
+		c.Check(err, IsNil)
+		c.Check(event, Equals, d.Event)
+		c.Check(offset, Equals, d.Offset)
+		if event != NoSolarEvent {
+			c.Check(formatSolarStart(event, offset), Equals, d.Format)
+		}
+	}
+	_, _, err := parseSolarStart("sunset+foo")
+	c.Check(err, ErrorMatches, "invalid sunset offset '\\+foo': .*")
+}
+
+func (s *SolarSuite) TestEventTime(c *C) {
+	testdata := []struct {
+		Name                string
+		Latitude, Longitude float64
+		Date                time.Time
+		Sunrise, Sunset     time.Time
+	}{
+		{
+			Name:      "London summer solstice",
+			Latitude:  51.51,
+			Longitude: -0.13,
+			Date:      time.Date(2021, 6, 21, 0, 0, 0, 0, time.UTC),
+			// 04:43 and 21:21 BST (UTC+1)
+			Sunrise: time.Date(2021, 6, 21, 3, 43, 0, 0, time.UTC),
+			Sunset:  time.Date(2021, 6, 21, 20, 21, 0, 0, time.UTC),
+		},
+		{
+			Name:      "London winter solstice",
+			Latitude:  51.51,
+			Longitude: -0.13,
+			Date:      time.Date(2021, 12, 21, 0, 0, 0, 0, time.UTC),
+			Sunrise:   time.Date(2021, 12, 21, 8, 4, 0, 0, time.UTC),
+			Sunset:    time.Date(2021, 12, 21, 15, 53, 0, 0, time.UTC),
+		},
+		{
+			Name:      "Sydney",
+			Latitude:  -33.87,
+			Longitude: 151.21,
+			Date:      time.Date(2021, 6, 21, 0, 0, 0, 0, time.UTC),
+			// 07:00 and 16:54 AEST (UTC+10)
+			Sunrise: time.Date(2021, 6, 20, 21, 0, 0, 0, time.UTC),
+			Sunset:  time.Date(2021, 6, 21, 6, 54, 0, 0, time.UTC),
+		},
+	}
+
+	within := func(a, b time.Time) bool {
+		d := a.Sub(b)
+		return d < 3*time.Minute && d > -3*time.Minute
+	}
+
+	for _, d := range testdata {
+		y, m, day := d.Date.Date()
+		sunrise, ok := SolarEventTime(Sunrise, y, m, day, d.Latitude, d.Longitude)
+		if c.Check(ok, Equals, true, Commentf(d.Name)) == true {
+			c.Check(within(sunrise, d.Sunrise), Equals, true, Commentf("%s: sunrise is %s, expected %s", d.Name, sunrise, d.Sunrise))
+		}
+		sunset, ok := SolarEventTime(Sunset, y, m, day, d.Latitude, d.Longitude)
+		if c.Check(ok, Equals, true, Commentf(d.Name)) == true {
+			c.Check(within(sunset, d.Sunset), Equals, true, Commentf("%s: sunset is %s, expected %s", d.Name, sunset, d.Sunset))
+		}
+	}
+
+	// Tromsø has polar days and nights
+	_, ok := SolarEventTime(Sunset, 2021, 6, 21, 69.65, 18.96)
+	c.Check(ok, Equals, false)
+	_, ok = SolarEventTime(Sunrise, 2021, 12, 21, 69.65, 18.96)
+	c.Check(ok, Equals, false)
+	_, ok = SolarEventTime(NoSolarEvent, 2021, 12, 21, 46.52, 6.63)
+	c.Check(ok, Equals, false)
+}
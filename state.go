@@ -1,5 +1,10 @@
 package zeus
 
+import (
+	"fmt"
+	"math"
+)
+
 type State struct {
 	Name         string
 	Temperature  Temperature
@@ -7,60 +12,100 @@ type State struct {
 	Wind         Wind
 	VisibleLight Light
 	UVLight      Light
+	// Drift, if set, makes some fields vary with the experiment
+	// day. The other fields are then the values for the first day.
+	Drift *StateDrift
+	// HumidityTarget, if set, replaces Humidity by a value computed
+	// from the temperature.
+	HumidityTarget *HumidityTarget
+	// Channels holds the values of registered channels other than
+	// the built-in fields. They are accessed through their Channel.
+	Channels *ChannelValues
 }
 
 func (s *State) UnmarshalYAML(unmarshal func(interface{}) error) error {
 	type stateYAML struct {
-		Name         string
-		Temperature  float64 `yaml:"temperature"`
-		Humidity     float64 `yaml:"humidity"`
-		Wind         float64 `yaml:"wind"`
-		VisibleLight float64 `yaml:"visible-light"`
-		UVLight      float64 `yaml:"uv-light"`
+		Name     string
+		VPD      *float64              `yaml:"vpd"`
+		Absolute *float64              `yaml:"absolute-humidity"`
+		Values   map[string]driftValue `yaml:",inline"`
 	}
 
 	res := stateYAML{}
-	res.Temperature = UndefinedTemperature.Value()
-	res.Humidity = UndefinedHumidity.Value()
-	res.Wind = UndefinedWind.Value()
-	res.VisibleLight = UndefinedLight.Value()
-	res.UVLight = UndefinedLight.Value()
-
 	if err := unmarshal(&res); err != nil {
 		return err
 	}
-	s.Name = res.Name
-	s.Temperature = Temperature(res.Temperature)
-	s.Humidity = Humidity(res.Humidity)
-	s.Wind = Wind(res.Wind)
-	s.VisibleLight = Light(res.VisibleLight)
-	s.UVLight = Light(res.UVLight)
+	*s = State{Name: res.Name}
+	drift := &StateDrift{}
+	for _, c := range channels {
+		c.set(s, math.Inf(-1))
+	}
+	for name, v := range res.Values {
+		c, ok := LookupChannel(name)
+		if ok == false {
+			return fmt.Errorf("state '%s': unknown channel '%s'", res.Name, name)
+		}
+		c.set(s, v.Value)
+		if v.Table != nil {
+			c.setTable(drift, v.Table)
+		}
+	}
+	if drift.empty() == false {
+		s.Drift = drift
+	}
+	return s.unmarshalHumidityTarget(res.VPD, res.Absolute)
+}
+
+func (s *State) unmarshalHumidityTarget(vpd, absolute *float64) error {
+	s.HumidityTarget = nil
+	if vpd == nil && absolute == nil {
+		return nil
+	}
+	if vpd != nil && absolute != nil {
+		return fmt.Errorf("state '%s' defines both vpd and absolute-humidity", s.Name)
+	}
+	if IsUndefined(s.Humidity) == false || (s.Drift != nil && s.Drift.Humidity != nil) {
+		return fmt.Errorf("state '%s' defines both humidity and a vpd or absolute-humidity", s.Name)
+	}
+	if IsUndefined(s.Temperature) == true {
+		return fmt.Errorf("state '%s' requires a temperature to define a vpd or absolute-humidity", s.Name)
+	}
+	if vpd != nil {
+		if *vpd <= 0 {
+			return fmt.Errorf("state '%s': invalid vpd %g: should be positive", s.Name, *vpd)
+		}
+		s.HumidityTarget = &HumidityTarget{VaporPressureDeficit: *vpd}
+		return nil
+	}
+	if *absolute <= 0 {
+		return fmt.Errorf("state '%s': invalid absolute-humidity %g: should be positive", s.Name, *absolute)
+	}
+	s.HumidityTarget = &HumidityTarget{AbsoluteHumidity: *absolute}
 	return nil
 }
 
 func (s State) MarshalYAML() (interface{}, error) {
 	type saveState struct {
 		Name   string
-		Values map[string]float64 `yaml:",inline"`
+		Values map[string]interface{} `yaml:",inline"`
 	}
 	res := saveState{
 		Name:   s.Name,
-		Values: make(map[string]float64),
-	}
-	if !IsUndefined(s.Temperature) {
-		res.Values["temperature"] = float64(s.Temperature)
-	}
-	if !IsUndefined(s.Humidity) {
-		res.Values["humidity"] = float64(s.Humidity)
-	}
-	if !IsUndefined(s.Wind) {
-		res.Values["wind"] = float64(s.Wind)
+		Values: make(map[string]interface{}),
 	}
-	if !IsUndefined(s.VisibleLight) {
-		res.Values["visible-light"] = float64(s.VisibleLight)
+	for _, c := range channels {
+		if table := c.Drift(s.Drift); table != nil {
+			res.Values[c.Name] = table
+		} else if v := c.Value(s); IsUndefined(v) == false {
+			res.Values[c.Name] = v.Value()
+		}
 	}
-	if !IsUndefined(s.UVLight) {
-		res.Values["uv-light"] = float64(s.UVLight)
+	if s.HumidityTarget != nil {
+		if s.HumidityTarget.AbsoluteHumidity > 0 {
+			res.Values["absolute-humidity"] = s.HumidityTarget.AbsoluteHumidity
+		} else {
+			res.Values["vpd"] = s.HumidityTarget.VaporPressureDeficit
+		}
 	}
 	return res, nil
 }
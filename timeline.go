@@ -0,0 +1,65 @@
+package zeus
+
+import "time"
+
+// Segment is a part of a schedule where the climate is either static
+// in a state, or transitions between two states.
+type Segment struct {
+	// From is the state at the beginning of the segment, and To the
+	// state at its end. They are the same for a static segment.
+	From, To string
+	// Start and End of the segment, clipped to the queried period.
+	Start, End time.Time
+	// Transition is true if the climate transitions from From to To.
+	Transition    bool
+	Interpolation Interpolation
+}
+
+func newSegment(i Interpolation, start time.Time) Segment {
+	if d, ok := DescribeTransition(i); ok == true {
+		return Segment{From: d.From, To: d.To, Start: start, Transition: true, Interpolation: i}
+	}
+	name := i.State(start).Name
+	return Segment{From: name, To: name, Start: start, Interpolation: i}
+}
+
+// Timeline returns the ordered segments of the schedule of i between
+// start and end. Instantaneous transitions are returned as segments
+// starting and ending at the same time.
+func Timeline(i ClimateInterpoler, start, end time.Time) []Segment {
+	var res []Segment
+	segmentStart, t := start, start
+	for segmentStart.Before(end) {
+		current, next, nextInterpolation := i.CurrentInterpolation(t)
+		segment := newSegment(current, segmentStart)
+		if nextInterpolation == nil || next.Before(end) == false || next.After(segmentStart) == false {
+			segment.End = end
+			res = append(res, segment)
+			break
+		}
+		segment.End = next
+		res = append(res, segment)
+		if d, ok := DescribeTransition(nextInterpolation); ok == true && d.Duration == 0 {
+			instant := newSegment(nextInterpolation, next)
+			instant.End = next
+			res = append(res, instant)
+		}
+		// the boundary belongs to the previous segment
+		segmentStart, t = next, next.Add(time.Nanosecond)
+	}
+	return res
+}
+
+// NextEntry returns the segment where the state name is next entered
+// after t, looking until limit. It is usually a transition, or the
+// static segment of a state entered at the experiment end.
+func NextEntry(i ClimateInterpoler, name string, t, limit time.Time) (Segment, bool) {
+	segments := Timeline(i, t, limit)
+	for k := 1; k < len(segments); k++ {
+		if segments[k].To != name || segments[k-1].To == name {
+			continue
+		}
+		return segments[k], true
+	}
+	return Segment{}, false
+}
@@ -0,0 +1,91 @@
+package zeus
+
+import (
+	"time"
+
+	. "gopkg.in/check.v1"
+)
+
+type TimelineSuite struct{}
+
+var _ = Suite(&TimelineSuite{})
+
+func (s *TimelineSuite) TestTimeline(c *C) {
+	at := func(h int) time.Time { return time.Date(0, 1, 1, h, 0, 0, 0, time.UTC) }
+	climate := ZoneClimate{
+		States: []State{{Name: "night"}, {Name: "day"}, {Name: "safe"}},
+		Transitions: []Transition{
+			{From: "night", To: "day", Start: at(6), Duration: time.Hour},
+			{From: "day", To: "night", Start: at(18)},
+		},
+		End: &ExperimentEnd{Day: 2, Time: at(12), State: "safe"},
+	}
+	reference := time.Date(2021, 3, 1, 0, 0, 0, 0, time.UTC)
+	date := func(day, h int) time.Time { return reference.AddDate(0, 0, day-1).Add(time.Duration(h) * time.Hour) }
+	i, err := NewZoneClimateInterpoler(climate, reference)
+	c.Assert(err, IsNil)
+
+	type segment struct {
+		From, To   string
+		Start, End time.Time
+		Transition bool
+	}
+	segments := []segment{}
+	for _, s := range Timeline(i, date(1, 0).Add(30*time.Minute), date(3, 0)) {
+		segments = append(segments, segment{s.From, s.To, s.Start, s.End, s.Transition})
+	}
+	c.Check(segments, DeepEquals, []segment{
+		{"night", "night", date(1, 0).Add(30 * time.Minute), date(1, 6), false},
+		{"night", "day", date(1, 6), date(1, 7), true},
+		{"day", "day", date(1, 7), date(1, 18), false},
+		{"day", "night", date(1, 18), date(1, 18), true},
+		{"night", "night", date(1, 18), date(2, 6), false},
+		{"night", "day", date(2, 6), date(2, 7), true},
+		{"day", "day", date(2, 7), date(2, 12), false},
+		{"safe", "safe", date(2, 12), date(3, 0), false},
+	})
+
+	// segments are clipped to the queried period
+	timeline := Timeline(i, date(1, 6).Add(30*time.Minute), date(1, 12))
+	c.Assert(len(timeline), Equals, 2)
+	c.Check(timeline[0].Start, Equals, date(1, 6).Add(30*time.Minute))
+	c.Check(timeline[0].Transition, Equals, true)
+	c.Check(timeline[1].End, Equals, date(1, 12))
+
+	entry, ok := NextEntry(i, "day", date(1, 6).Add(30*time.Minute), date(3, 0))
+	c.Check(ok, Equals, true)
+	c.Check(entry.Start, Equals, date(2, 6))
+	c.Check(entry.End, Equals, date(2, 7))
+
+	entry, ok = NextEntry(i, "night", date(1, 12), date(3, 0))
+	c.Check(ok, Equals, true)
+	c.Check(entry.Start, Equals, date(1, 18))
+	c.Check(entry.End, Equals, date(1, 18))
+
+	entry, ok = NextEntry(i, "safe", date(1, 0), date(3, 0))
+	c.Check(ok, Equals, true)
+	c.Check(entry.Transition, Equals, false)
+	c.Check(entry.Start, Equals, date(2, 12))
+
+	_, ok = NextEntry(i, "day", date(2, 8), date(3, 0))
+	c.Check(ok, Equals, false)
+}
+
+func (s *TimelineSuite) TestShiftedTimeline(c *C) {
+	at := func(h int) time.Time { return time.Date(0, 1, 1, h, 0, 0, 0, time.UTC) }
+	climate := ZoneClimate{
+		States: []State{{Name: "night"}, {Name: "day"}},
+		Transitions: []Transition{
+			{From: "night", To: "day", Start: at(6), Duration: time.Hour},
+			{From: "day", To: "night", Start: at(18), Duration: time.Hour},
+		},
+		TimeOffset: 2 * time.Hour,
+	}
+	reference := time.Date(2021, 3, 1, 0, 0, 0, 0, time.UTC)
+	i, err := NewZoneClimateInterpoler(climate, reference)
+	c.Assert(err, IsNil)
+	entry, ok := NextEntry(i, "day", reference, reference.AddDate(0, 0, 1))
+	c.Check(ok, Equals, true)
+	c.Check(entry.Start, Equals, reference.Add(8*time.Hour))
+	c.Check(entry.From, Equals, "night")
+}
@@ -11,6 +11,21 @@ type Transition struct {
 	Start          time.Time
 	StartTimeDelta time.Duration
 	Day            int
+	Curve          TransitionCurve
+	// SolarEvent, if set, replaces Start by the time of the sunrise
+	// or sunset, shifted by SolarOffset.
+	SolarEvent  SolarEvent
+	SolarOffset time.Duration
+	// Recurrence, if set, restricts the days on which a recurring
+	// transition occurs.
+	Recurrence *Recurrence
+	// Priority chooses which transition occurs when several ones
+	// leave the same state at the same time.
+	Priority int
+
+	// variantOf is set on transitions generated by a Weather
+	// variant: it is the state the variant replaces.
+	variantOf string
 }
 
 func (t *Transition) Check() error {
@@ -20,6 +35,17 @@ func (t *Transition) Check() error {
 	if (t.Day > 0) && t.StartTimeDelta != 0 {
 		return fmt.Errorf("StartTimeDelta is only available for recurring transitions (Day!=0)")
 	}
+	if t.Day > 0 && t.Recurrence != nil {
+		return fmt.Errorf("Recurrence is only available for recurring transitions (Day!=0)")
+	}
+	if t.Recurrence != nil {
+		if err := t.Recurrence.Check(); err != nil {
+			return err
+		}
+	}
+	if t.SolarEvent != NoSolarEvent && t.StartTimeDelta != 0 {
+		return fmt.Errorf("StartTimeDelta cannot be used with a %s start time", t.SolarEvent)
+	}
 
 	return nil
 }
@@ -31,6 +57,9 @@ type transitionShadow struct {
 	Day            int `yaml:"day,omitempty"`
 	Duration       time.Duration
 	StartTimeDelta time.Duration `yaml:"start-time-delta,omitempty"`
+	Curve          string        `yaml:"curve,omitempty"`
+	Recurrence     *Recurrence   `yaml:"recurrence,omitempty"`
+	Priority       int           `yaml:"priority,omitempty"`
 }
 
 func (t *Transition) UnmarshalYAML(unmarshal func(interface{}) error) error {
@@ -43,29 +72,88 @@ func (t *Transition) UnmarshalYAML(unmarshal func(interface{}) error) error {
 	t.Duration = shadow.Duration
 	t.StartTimeDelta = shadow.StartTimeDelta
 	var err error
-	t.Start, err = time.Parse("15:04", shadow.Start)
+	t.SolarEvent, t.SolarOffset, err = parseSolarStart(shadow.Start)
 	if err != nil {
 		return err
 	}
+	if t.SolarEvent == NoSolarEvent {
+		t.Start, err = time.Parse("15:04", shadow.Start)
+		if err != nil {
+			return err
+		}
+	}
 	t.Day = shadow.Day
+	t.Recurrence = shadow.Recurrence
+	t.Priority = shadow.Priority
+	t.Curve, err = ParseTransitionCurve(shadow.Curve)
+	if err != nil {
+		return err
+	}
 
 	return t.Check()
 }
 
 func (t Transition) MarshalYAML() (interface{}, error) {
-	return transitionShadow{
+	res := transitionShadow{
 		From:           t.From,
 		To:             t.To,
-		Start:          t.Start.Format("15:04"),
+		Start:          t.StartString(),
 		Day:            t.Day,
 		Duration:       t.Duration,
 		StartTimeDelta: t.StartTimeDelta,
-	}, nil
+		Recurrence:     t.Recurrence,
+		Priority:       t.Priority,
+	}
+	if t.Curve != LinearCurve {
+		res.Curve = t.Curve.String()
+	}
+	return res, nil
+}
+
+// specificity orders transitions triggering at the same time with
+// the same Priority: the most specific one occurs.
+func (t Transition) specificity() int {
+	if t.Day != 0 {
+		return 2
+	}
+	if t.Recurrence != nil {
+		return 1
+	}
+	return 0
+}
+
+// outranks returns true if t occurs instead of o when both trigger
+// at the same time: the one with the highest Priority occurs, then
+// the most specific one.
+func (t Transition) outranks(o Transition) bool {
+	if t.Priority != o.Priority {
+		return t.Priority > o.Priority
+	}
+	return t.specificity() > o.specificity()
+}
+
+// StartString formats the start time of the transition, as it is
+// written in season files.
+func (t Transition) StartString() string {
+	if t.SolarEvent != NoSolarEvent {
+		return formatSolarStart(t.SolarEvent, t.SolarOffset)
+	}
+	return t.Start.Format("15:04")
 }
 
 func (t Transition) String() string {
+	curve := ""
+	if t.Recurrence != nil {
+		curve = ", Recurrence: " + t.Recurrence.String()
+	}
+	if t.Curve != LinearCurve {
+		curve += ", Curve: " + t.Curve.String()
+	}
+	if t.Priority != 0 {
+		curve += fmt.Sprintf(", Priority: %d", t.Priority)
+	}
 	if t.Day == 0 {
-		return fmt.Sprintf("RecurringTransition{From: %s, To: %s, Start: %s, Duration: %s}", t.From, t.To, t.Start.Format("15:04"), t.Duration)
+		return fmt.Sprintf("RecurringTransition{From: %s, To: %s, Start: %s, Duration: %s%s}", t.From, t.To, t.StartString(), t.Duration, curve)
 	}
-	return fmt.Sprintf("Transition{From: %s, To: %s, Start: %s, OnDay: %d, Duration: %s}", t.From, t.To, t.Start.Format("15:04"), t.Day, t.Duration)
+	return fmt.Sprintf("Transition{From: %s, To: %s, Start: %s, OnDay: %d, Duration: %s%s}", t.From, t.To, t.StartString(), t.Day, t.Duration, curve)
 }
@@ -50,6 +50,70 @@ day: 3
 				Day:      3,
 			},
 		},
+		{
+			Text: `from: night
+to: day
+duration: 1h
+start: 06:00
+curve: sigmoid
+`,
+			Transition: Transition{
+				From:     "night",
+				To:       "day",
+				Duration: time.Hour,
+				Start:    time.Date(0, 1, 1, 6, 00, 0, 0, time.UTC),
+				Curve:    SigmoidCurve,
+			},
+		},
+		{
+			Text: `from: night
+to: day
+duration: 1h
+start: 06:00
+priority: 2
+`,
+			Transition: Transition{
+				From:     "night",
+				To:       "day",
+				Duration: time.Hour,
+				Start:    time.Date(0, 1, 1, 6, 00, 0, 0, time.UTC),
+				Priority: 2,
+			},
+		},
+		{
+			Text: `from: night
+to: day
+duration: 1h
+start: sunrise-30m
+`,
+			Transition: Transition{
+				From:        "night",
+				To:          "day",
+				Duration:    time.Hour,
+				SolarEvent:  Sunrise,
+				SolarOffset: -30 * time.Minute,
+			},
+		},
+		{
+			Text: `from: night
+to: cloudy-day
+duration: 1h
+start: 06:00
+recurrence:
+  weekdays: [mon, fri]
+  to-day: 20
+`,
+			Transition: Transition{
+				From:     "night",
+				To:       "cloudy-day",
+				Duration: time.Hour,
+				Start:    time.Date(0, 1, 1, 6, 00, 0, 0, time.UTC),
+				Recurrence: &Recurrence{
+					Weekdays: []time.Weekday{time.Monday, time.Friday},
+					ToDay:    20,
+				},
+			},
+		},
 	}
 
 	for _, d := range testdata {
@@ -94,6 +158,29 @@ day: 3
 start-time-delta: 3m`,
 			ErrorMatches: "StartTimeDelta is only available for recurring transitions",
 		},
+		{
+			Text: `from: a
+to: b
+start: 08:00
+curve: bounce`,
+			ErrorMatches: "unknown transition curve 'bounce'",
+		},
+		{
+			Text: `from: a
+to: b
+start: sunset
+start-time-delta: 2m`,
+			ErrorMatches: "StartTimeDelta cannot be used with a sunset start time",
+		},
+		{
+			Text: `from: a
+to: b
+start: 08:00
+day: 3
+recurrence:
+  every: 2`,
+			ErrorMatches: "Recurrence is only available for recurring transitions",
+		},
 	}
 
 	for _, d := range errordata {
@@ -141,6 +228,57 @@ to: b
 start: "10:30"
 day: 2
 duration: 30m0s
+`,
+		},
+		{
+			Transition: Transition{
+				From:     "a",
+				To:       "b",
+				Start:    time.Date(0, 1, 1, 6, 0, 0, 0, time.UTC),
+				Duration: time.Hour,
+				Curve:    CosineCurve,
+			},
+			ExpectedString: "RecurringTransition{From: a, To: b, Start: 06:00, Duration: 1h0m0s, Curve: cosine}",
+			ExpectedYAML: `from: a
+to: b
+start: "06:00"
+duration: 1h0m0s
+curve: cosine
+`,
+		},
+		{
+			Transition: Transition{
+				From:        "a",
+				To:          "b",
+				Day:         3,
+				Duration:    time.Hour,
+				SolarEvent:  Sunset,
+				SolarOffset: 15 * time.Minute,
+			},
+			ExpectedString: "Transition{From: a, To: b, Start: sunset+15m0s, OnDay: 3, Duration: 1h0m0s}",
+			ExpectedYAML: `from: a
+to: b
+start: sunset+15m0s
+day: 3
+duration: 1h0m0s
+`,
+		},
+		{
+			Transition: Transition{
+				From:       "a",
+				To:         "b",
+				Start:      time.Date(0, 1, 1, 6, 0, 0, 0, time.UTC),
+				Duration:   time.Hour,
+				Recurrence: &Recurrence{Every: 3, FromDay: 10},
+			},
+			ExpectedString: "RecurringTransition{From: a, To: b, Start: 06:00, Duration: 1h0m0s, Recurrence: every 3 days from day 10}",
+			ExpectedYAML: `from: a
+to: b
+start: "06:00"
+duration: 1h0m0s
+recurrence:
+  every: 3
+  from-day: 10
 `,
 		},
 	}
@@ -0,0 +1,159 @@
+package zeus
+
+import (
+	"fmt"
+	"hash/fnv"
+	"math"
+)
+
+// WeatherVariant replaces State by Variant, with the given
+// Probability, on the days the zone enters State.
+type WeatherVariant struct {
+	State       string  `yaml:"state"`
+	Variant     string  `yaml:"variant"`
+	Probability float64 `yaml:"probability"`
+}
+
+// WeatherJitter are the maximal random offsets applied to the
+// temperature and humidity of all states.
+type WeatherJitter struct {
+	Temperature float64 `yaml:"temperature,omitempty"`
+	Humidity    float64 `yaml:"humidity,omitempty"`
+}
+
+// Weather randomly modifies the climate of a zone every day. All
+// random draws are determined by Seed and the experiment day, so the
+// same Seed always produces the same schedule.
+type Weather struct {
+	Seed     int64            `yaml:"seed"`
+	Variants []WeatherVariant `yaml:"variants,omitempty"`
+	Jitter   WeatherJitter    `yaml:"jitter,omitempty"`
+}
+
+// Check checks the weather against the states of its zone.
+func (w *Weather) Check(states []State) error {
+	names := make(map[string]bool)
+	for _, s := range states {
+		names[s.Name] = true
+	}
+	total := make(map[string]float64)
+	for _, v := range w.Variants {
+		if names[v.State] == false {
+			return fmt.Errorf("Undefined state '%s' in weather variant", v.State)
+		}
+		if names[v.Variant] == false {
+			return fmt.Errorf("Undefined state '%s' in weather variant", v.Variant)
+		}
+		if v.State == v.Variant {
+			return fmt.Errorf("state '%s' cannot be its own weather variant", v.State)
+		}
+		if v.Probability < 0.0 || v.Probability > 1.0 {
+			return fmt.Errorf("invalid probability %g for weather variant '%s': should be in [0,1]", v.Probability, v.Variant)
+		}
+		total[v.State] += v.Probability
+		if total[v.State] > 1.0 {
+			return fmt.Errorf("probabilities of the weather variants of '%s' sum to more than 1", v.State)
+		}
+	}
+	if w.Jitter.Temperature < 0.0 || w.Jitter.Humidity < 0.0 {
+		return fmt.Errorf("weather jitter should be positive")
+	}
+	return nil
+}
+
+func weatherSalt(name string) uint64 {
+	h := fnv.New64a()
+	h.Write([]byte(name))
+	return h.Sum64()
+}
+
+// random returns a number in [0,1) for the given day and salt. It
+// uses the splitmix64 finalizer, which is stable across platforms
+// and go versions.
+func (w *Weather) random(day int, salt uint64) float64 {
+	x := uint64(w.Seed) ^ (uint64(day) * 0x9e3779b97f4a7c15) ^ salt
+	x ^= x >> 30
+	x *= 0xbf58476d1ce4e5b9
+	x ^= x >> 27
+	x *= 0x94d049bb133111eb
+	x ^= x >> 31
+	return float64(x>>11) / float64(uint64(1)<<53)
+}
+
+// target returns the state reached on the given experiment day
+// instead of state.
+func (w *Weather) target(state string, day int) string {
+	if w == nil {
+		return state
+	}
+	draw := -1.0
+	cumulated := 0.0
+	for _, v := range w.Variants {
+		if v.State != state {
+			continue
+		}
+		if draw < 0.0 {
+			draw = w.random(day, weatherSalt(state))
+		}
+		cumulated += v.Probability
+		if draw < cumulated {
+			return v.Variant
+		}
+	}
+	return state
+}
+
+// jitter returns the offset of a value with the given maximal
+// amplitude for a fractional experiment day. A random offset is
+// drawn for the beginning of every day, and linearly interpolated in
+// between.
+func (w *Weather) jitter(day float64, amplitude float64, salt uint64) float64 {
+	if amplitude == 0.0 {
+		return 0.0
+	}
+	first := math.Floor(day)
+	a := (2.0*w.random(int(first), salt) - 1.0) * amplitude
+	b := (2.0*w.random(int(first)+1, salt) - 1.0) * amplitude
+	return interpolate(a, b, day-first)
+}
+
+// involves returns true if state may be replaced by a variant, or
+// is a variant.
+func (w *Weather) involves(state string) bool {
+	if w == nil {
+		return false
+	}
+	for _, v := range w.Variants {
+		if v.State == state || v.Variant == state {
+			return true
+		}
+	}
+	return false
+}
+
+// plausible returns false if state cannot be reached on the given
+// experiment day: it is either replaced by a variant, or a variant
+// that was not drawn.
+func (w *Weather) plausible(state string, day int) bool {
+	if w == nil {
+		return true
+	}
+	for _, v := range w.Variants {
+		if v.State == state && w.target(state, day) != state {
+			return false
+		}
+		if v.Variant == state && w.target(v.State, day) == state {
+			return true
+		}
+	}
+	for _, v := range w.Variants {
+		if v.Variant == state {
+			return false
+		}
+	}
+	return true
+}
+
+func (w *Weather) hasJitter() bool {
+	return w != nil && (w.Jitter.Temperature > 0.0 || w.Jitter.Humidity > 0.0)
+}
@@ -0,0 +1,162 @@
+package zeus
+
+import (
+	"math"
+	"time"
+
+	. "gopkg.in/check.v1"
+	yaml "gopkg.in/yaml.v2"
+)
+
+type WeatherSuite struct{}
+
+var _ = Suite(&WeatherSuite{})
+
+func (s *WeatherSuite) TestParsing(c *C) {
+	w := Weather{}
+	err := yaml.Unmarshal([]byte(`
+seed: 42
+variants:
+  - state: day
+    variant: cloudy-day
+    probability: 0.3
+jitter:
+  temperature: 0.5
+  humidity: 5
+`), &w)
+	c.Assert(err, IsNil)
+	c.Check(w, DeepEquals, Weather{
+		Seed:     42,
+		Variants: []WeatherVariant{{State: "day", Variant: "cloudy-day", Probability: 0.3}},
+		Jitter:   WeatherJitter{Temperature: 0.5, Humidity: 5},
+	})
+}
+
+func (s *WeatherSuite) TestCheck(c *C) {
+	states := []State{{Name: "day"}, {Name: "night"}, {Name: "cloudy-day"}, {Name: "rainy-day"}}
+	testdata := []struct {
+		Weather Weather
+		Error   string
+	}{
+		{
+			Weather: Weather{Variants: []WeatherVariant{{State: "foo", Variant: "day"}}},
+			Error:   "Undefined state 'foo' in weather variant",
+		},
+		{
+			Weather: Weather{Variants: []WeatherVariant{{State: "day", Variant: "bar"}}},
+			Error:   "Undefined state 'bar' in weather variant",
+		},
+		{
+			Weather: Weather{Variants: []WeatherVariant{{State: "day", Variant: "day"}}},
+			Error:   "state 'day' cannot be its own weather variant",
+		},
+		{
+			Weather: Weather{Variants: []WeatherVariant{{State: "day", Variant: "cloudy-day", Probability: 1.2}}},
+			Error:   `invalid probability 1.2 for weather variant 'cloudy-day': should be in \[0,1\]`,
+		},
+		{
+			Weather: Weather{Variants: []WeatherVariant{
+				{State: "day", Variant: "cloudy-day", Probability: 0.6},
+				{State: "day", Variant: "rainy-day", Probability: 0.6},
+			}},
+			Error: "probabilities of the weather variants of 'day' sum to more than 1",
+		},
+		{
+			Weather: Weather{Jitter: WeatherJitter{Humidity: -1}},
+			Error:   "weather jitter should be positive",
+		},
+	}
+	for _, d := range testdata {
+		c.Check(d.Weather.Check(states), ErrorMatches, d.Error)
+	}
+}
+
+func (s *WeatherSuite) TestDraws(c *C) {
+	w := &Weather{
+		Seed: 42,
+		Variants: []WeatherVariant{
+			{State: "day", Variant: "cloudy-day", Probability: 0.3},
+			{State: "day", Variant: "rainy-day", Probability: 0.1},
+		},
+		Jitter: WeatherJitter{Temperature: 0.5},
+	}
+	other := &Weather{Seed: 43, Variants: w.Variants}
+	counts := map[string]int{}
+	differences := 0
+	days := 10000
+	for day := 1; day <= days; day++ {
+		target := w.target("day", day)
+		c.Check(w.target("day", day), Equals, target)
+		c.Check(w.target("night", day), Equals, "night")
+		counts[target] += 1
+		if other.target("day", day) != target {
+			differences += 1
+		}
+	}
+	c.Check(math.Abs(float64(counts["cloudy-day"])/float64(days)-0.3) < 0.02, Equals, true, Commentf("%v", counts))
+	c.Check(math.Abs(float64(counts["rainy-day"])/float64(days)-0.1) < 0.02, Equals, true, Commentf("%v", counts))
+	c.Check(differences > 0, Equals, true)
+
+	last := w.jitter(1.0, w.Jitter.Temperature, weatherSalt("temperature"))
+	for day := 1.0; day < 30.0; day += 0.01 {
+		offset := w.jitter(day, w.Jitter.Temperature, weatherSalt("temperature"))
+		c.Check(math.Abs(offset) <= 0.5, Equals, true)
+		// jitter is continuous
+		c.Check(math.Abs(offset-last) < 0.02, Equals, true)
+		last = offset
+	}
+}
+
+func (s *WeatherSuite) TestInterpolation(c *C) {
+	at := func(h int) time.Time { return time.Date(0, 1, 1, h, 0, 0, 0, time.UTC) }
+	weather := &Weather{
+		Seed:     1234,
+		Variants: []WeatherVariant{{State: "day", Variant: "cloudy-day", Probability: 0.4}},
+		Jitter:   WeatherJitter{Temperature: 1.0},
+	}
+	climate := ZoneClimate{
+		States: []State{
+			{Name: "day", Temperature: 26, Humidity: 60},
+			{Name: "night", Temperature: 22, Humidity: 60},
+			{Name: "cloudy-day", Temperature: 21, Humidity: 80},
+		},
+		Transitions: []Transition{
+			{From: "night", To: "day", Start: at(6)},
+			{From: "day", To: "night", Start: at(17)},
+			{From: "cloudy-day", To: "night", Start: at(17)},
+		},
+		Weather: weather,
+	}
+	reference := time.Date(2021, 3, 1, 0, 0, 0, 0, time.UTC)
+	forward, err := NewZoneClimateInterpoler(climate, reference)
+	c.Assert(err, IsNil)
+
+	cloudy := 0
+	for day := 1; day <= 30; day++ {
+		noon := reference.AddDate(0, 0, day-1).Add(12 * time.Hour)
+		expected := weather.target("day", day)
+		if expected == "cloudy-day" {
+			cloudy += 1
+		}
+		current, _, _ := forward.CurrentInterpolation(noon)
+		state := current.State(noon)
+		c.Check(state.Name, Equals, expected, Commentf("day %d", day))
+		base, humidity := 26.0, 60.0
+		if expected == "cloudy-day" {
+			base, humidity = 21.0, 80.0
+		}
+		c.Check(math.Abs(state.Temperature.Value()-base) <= 1.0, Equals, true)
+		c.Check(state.Temperature.Value(), Not(Equals), base)
+		// no humidity jitter
+		c.Check(state.Humidity.Value(), Equals, humidity)
+
+		// a new interpoler gives the same schedule, and can walk
+		// backward.
+		other, err := NewZoneClimateInterpoler(climate, reference)
+		c.Assert(err, IsNil)
+		other.CurrentInterpolation(noon.AddDate(0, 0, 3))
+		current, _, _ = other.CurrentInterpolation(noon)
+		c.Check(current.State(noon), Equals, state, Commentf("day %d", day))
+	}
+	c.Check(cloudy > 0 && cloudy < 30, Equals, true)
+}
@@ -0,0 +1,146 @@
+package zeus
+
+import (
+	"bufio"
+	"bytes"
+	"fmt"
+	"strings"
+)
+
+// yamlPositions maps the paths of the nodes of a block style YAML
+// document, like "zones.box.states[1].temperature", to their line
+// numbers. yaml.v2 does not expose node positions, so a simple
+// indentation based scanner is used: flow collections are not
+// descended into.
+type yamlPositions map[string]int
+
+type yamlFrame struct {
+	path        string
+	keyIndent   int
+	childIndent int
+	count       int
+}
+
+func yamlStripComment(line string) string {
+	quote := rune(0)
+	for i, c := range line {
+		switch {
+		case quote != 0:
+			if c == quote {
+				quote = 0
+			}
+		case c == '\'' || c == '"':
+			quote = c
+		case c == '#' && (i == 0 || line[i-1] == ' ' || line[i-1] == '\t'):
+			return strings.TrimRight(line[:i], " \t")
+		}
+	}
+	return strings.TrimRight(line, " \t")
+}
+
+// yamlSplitKey splits a "key: value" content. It returns false if
+// content is not a mapping entry.
+func yamlSplitKey(content string) (string, string, bool) {
+	if strings.HasPrefix(content, "{") || strings.HasPrefix(content, "[") {
+		return "", "", false
+	}
+	quote := rune(0)
+	for i, c := range content {
+		switch {
+		case quote != 0:
+			if c == quote {
+				quote = 0
+			}
+		case c == '\'' || c == '"':
+			quote = c
+		case c == ':' && (i == len(content)-1 || content[i+1] == ' '):
+			key := strings.Trim(strings.TrimSpace(content[:i]), `'"`)
+			return key, strings.TrimSpace(content[i+1:]), true
+		}
+	}
+	return "", "", false
+}
+
+func joinYAMLPath(parent, key string) string {
+	if len(parent) == 0 {
+		return key
+	}
+	return parent + "." + key
+}
+
+func parseYAMLPositions(data []byte) yamlPositions {
+	res := yamlPositions{}
+	stack := []*yamlFrame{{keyIndent: -1, childIndent: -1}}
+	scanner := bufio.NewScanner(bytes.NewReader(data))
+	lineNumber := 0
+	for scanner.Scan() {
+		lineNumber++
+		line := yamlStripComment(scanner.Text())
+		content := strings.TrimLeft(line, " ")
+		if len(content) == 0 || content == "---" {
+			continue
+		}
+		indent := len(line) - len(content)
+		for len(content) > 0 {
+			isItem := content == "-" || strings.HasPrefix(content, "- ")
+			for len(stack) > 1 {
+				top := stack[len(stack)-1]
+				// a sequence may be at the same indentation than its key
+				sameAsKey := indent == top.keyIndent && isItem == false
+				if top.childIndent >= 0 && indent >= top.childIndent && sameAsKey == false {
+					break
+				}
+				if top.childIndent < 0 && (indent > top.keyIndent || (indent == top.keyIndent && isItem)) {
+					break
+				}
+				stack = stack[:len(stack)-1]
+			}
+			top := stack[len(stack)-1]
+			if top.childIndent < 0 {
+				top.childIndent = indent
+			}
+			if isItem == true {
+				path := fmt.Sprintf("%s[%d]", top.path, top.count)
+				top.count++
+				res[path] = lineNumber
+				rest := strings.TrimLeft(strings.TrimPrefix(content, "-"), " ")
+				newIndent := indent + len(content) - len(rest)
+				stack = append(stack, &yamlFrame{path: path, keyIndent: indent, childIndent: newIndent})
+				content, indent = rest, newIndent
+				continue
+			}
+			key, value, ok := yamlSplitKey(content)
+			if ok == false {
+				break
+			}
+			path := joinYAMLPath(top.path, key)
+			res[path] = lineNumber
+			if len(value) == 0 || value == "|" || value == ">" {
+				stack = append(stack, &yamlFrame{path: path, keyIndent: indent, childIndent: -1})
+			}
+			break
+		}
+	}
+	return res
+}
+
+// yamlParent returns the path of the parent of the node at path, or
+// an empty string for a top-level node.
+func yamlParent(path string) string {
+	idx := strings.LastIndexAny(path, ".[")
+	if idx < 0 {
+		return ""
+	}
+	return path[:idx]
+}
+
+// Line returns the line of path, or of its closest parent. It
+// returns 0 if none is found.
+func (p yamlPositions) Line(path string) int {
+	for ; len(path) > 0; path = yamlParent(path) {
+		if line, ok := p[path]; ok == true {
+			return line
+		}
+	}
+	return 0
+}
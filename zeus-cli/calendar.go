@@ -0,0 +1,93 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"time"
+
+	"github.com/formicidae-tracker/zeus"
+	"github.com/jessevdk/go-flags"
+)
+
+type CalendarCommand struct {
+	StartDate string `long:"start-date" description:"first day of the calendar like 2006-01-02, using current date if left blank"`
+	Duration  int    `long:"duration" short:"d" description:"length of the calendar in days" default:"30"`
+	Output    string `long:"output" short:"o" description:"writes the calendar in this file instead of stdout"`
+
+	Args struct {
+		SeasonFile flags.Filename
+	} `positional-args:"yes" required:"true"`
+}
+
+// start returns the midnight of the first day of the calendar.
+func (c *CalendarCommand) start(now time.Time) (time.Time, error) {
+	y, m, d := now.Date()
+	if len(c.StartDate) > 0 {
+		date, err := time.Parse("2006-01-02", c.StartDate)
+		if err != nil {
+			return time.Time{}, err
+		}
+		y, m, d = date.Date()
+	}
+	return time.Date(y, m, d, 0, 0, 0, 0, now.Location()), nil
+}
+
+func (c *CalendarCommand) Execute(args []string) error {
+	if c.Duration <= 0 {
+		return fmt.Errorf("invalid duration %d: should be positive", c.Duration)
+	}
+	season, err := zeus.ReadSeasonFile(string(c.Args.SeasonFile), os.Stderr)
+	if err != nil {
+		return err
+	}
+	season.Zones, err = season.ResolveZones()
+	if err != nil {
+		return err
+	}
+	now := time.Now()
+	start, err := c.start(now)
+	if err != nil {
+		return err
+	}
+	end := start.AddDate(0, 0, c.Duration)
+
+	names := make([]string, 0, len(season.Zones))
+	for name := range season.Zones {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	var events []calendarEvent
+	for _, name := range names {
+		transitions, err := zoneTransitions(season.Zones[name], start, end)
+		if err != nil {
+			return fmt.Errorf("zone '%s': %s", name, err)
+		}
+		events = append(events, recurringEvents(name, transitions)...)
+	}
+
+	name := filepath.Base(string(c.Args.SeasonFile))
+	if len(c.Output) == 0 {
+		return writeCalendar(os.Stdout, name, events, now)
+	}
+	f, err := os.Create(c.Output)
+	if err != nil {
+		return err
+	}
+	if err := writeCalendar(f, name, events, now); err != nil {
+		f.Close()
+		return err
+	}
+	return f.Close()
+}
+
+func init() {
+	_, err := parser.AddCommand("calendar",
+		"exports the transitions of a season file as an iCalendar file",
+		"exports all transitions of a season file over some days as an iCalendar (.ics) file, with one event per transition and zone. Transitions repeated at the same time are exported as recurring events",
+		&CalendarCommand{})
+	if err != nil {
+		panic(err.Error())
+	}
+}
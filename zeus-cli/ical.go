@@ -0,0 +1,254 @@
+package main
+
+import (
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"github.com/formicidae-tracker/zeus"
+)
+
+// calendarEvent is a transition of a zone, possibly repeated every
+// Interval days or on Weekdays, Count times.
+type calendarEvent struct {
+	Zone       string
+	Transition zeus.TransitionDescription
+	Interval   int
+	Weekdays   []time.Weekday
+	Count      int
+}
+
+// zoneTransitions returns all the transitions of zone starting between
+// start and end.
+func zoneTransitions(zone zeus.ZoneClimate, start, end time.Time) ([]zeus.TransitionDescription, error) {
+	i, err := zeus.NewZoneClimateInterpoler(zone, start.UTC())
+	if err != nil {
+		return nil, err
+	}
+	var res []zeus.TransitionDescription
+	for _, s := range zeus.Timeline(i, start, end) {
+		// the first transition may have started before start
+		if d, ok := zeus.DescribeTransition(s.Interpolation); ok == true && d.Start.Before(start) == false {
+			res = append(res, d)
+		}
+	}
+	return res, nil
+}
+
+func daysBetween(a, b time.Time) int {
+	return int(b.Sub(a) / (24 * time.Hour))
+}
+
+// constantInterval returns the number of days between each
+// occurrence, if it is constant.
+func constantInterval(occurrences []zeus.TransitionDescription) (int, bool) {
+	interval := daysBetween(occurrences[0].Start, occurrences[1].Start)
+	for i := 2; i < len(occurrences); i++ {
+		if daysBetween(occurrences[i-1].Start, occurrences[i].Start) != interval {
+			return 0, false
+		}
+	}
+	return interval, true
+}
+
+// weeklyDays returns the weekdays of occurrences, if they occur on
+// every day of these weekdays between the first and the last one.
+func weeklyDays(occurrences []zeus.TransitionDescription) ([]time.Weekday, bool) {
+	weekdays := map[time.Weekday]bool{}
+	for _, o := range occurrences {
+		weekdays[o.Start.Weekday()] = true
+	}
+	if len(weekdays) == 7 {
+		return nil, false
+	}
+	j := 0
+	for t := occurrences[0].Start; t.After(occurrences[len(occurrences)-1].Start) == false; t = t.Add(24 * time.Hour) {
+		if weekdays[t.Weekday()] == false {
+			continue
+		}
+		if j >= len(occurrences) || occurrences[j].Start.Equal(t) == false {
+			return nil, false
+		}
+		j++
+	}
+	res := make([]time.Weekday, 0, len(weekdays))
+	for d := range weekdays {
+		res = append(res, d)
+	}
+	sort.Slice(res, func(i, j int) bool { return res[i] < res[j] })
+	return res, true
+}
+
+// recurringEvents groups the occurrences of a same transition, at the
+// same UTC time, into recurring events. Occurrences which cannot be
+// described by a daily or weekly recurrence, like one-off or solar
+// transitions, are kept as single events.
+func recurringEvents(zone string, occurrences []zeus.TransitionDescription) []calendarEvent {
+	type key struct {
+		From, To string
+		Duration time.Duration
+		Clock    time.Duration
+	}
+	var keys []key
+	groups := map[key][]zeus.TransitionDescription{}
+	for _, o := range occurrences {
+		start := o.Start.UTC()
+		k := key{o.From, o.To, o.Duration, start.Sub(start.Truncate(24 * time.Hour))}
+		if _, ok := groups[k]; ok == false {
+			keys = append(keys, k)
+		}
+		groups[k] = append(groups[k], o)
+	}
+
+	var res []calendarEvent
+	for _, k := range keys {
+		group := groups[k]
+		if len(group) == 1 {
+			res = append(res, calendarEvent{Zone: zone, Transition: group[0]})
+			continue
+		}
+		if interval, ok := constantInterval(group); ok == true {
+			res = append(res, calendarEvent{Zone: zone, Transition: group[0], Interval: interval, Count: len(group)})
+			continue
+		}
+		if weekdays, ok := weeklyDays(group); ok == true {
+			res = append(res, calendarEvent{Zone: zone, Transition: group[0], Weekdays: weekdays, Count: len(group)})
+			continue
+		}
+		// splits the group in runs of constant interval
+		for i := 0; i < len(group); {
+			j := i + 1
+			for j+1 < len(group) && daysBetween(group[j].Start, group[j+1].Start) == daysBetween(group[i].Start, group[i+1].Start) {
+				j++
+			}
+			if j >= len(group) {
+				res = append(res, calendarEvent{Zone: zone, Transition: group[i]})
+				break
+			}
+			res = append(res, calendarEvent{
+				Zone:       zone,
+				Transition: group[i],
+				Interval:   daysBetween(group[i].Start, group[j].Start) / (j - i),
+				Count:      j - i + 1,
+			})
+			i = j + 1
+		}
+	}
+	sort.SliceStable(res, func(i, j int) bool {
+		return res[i].Transition.Start.Before(res[j].Transition.Start)
+	})
+	return res
+}
+
+// escapeICalText escapes a TEXT value as specified by RFC 5545.
+func escapeICalText(s string) string {
+	return strings.NewReplacer(`\`, `\\`, ";", `\;`, ",", `\,`, "\n", `\n`).Replace(s)
+}
+
+func formatICalTime(t time.Time) string {
+	return t.UTC().Format("20060102T150405Z")
+}
+
+func formatICalDuration(d time.Duration) string {
+	if d <= 0 {
+		return "PT0S"
+	}
+	res := "P"
+	if days := d / (24 * time.Hour); days > 0 {
+		res += fmt.Sprintf("%dD", days)
+		d -= days * 24 * time.Hour
+	}
+	if d == 0 {
+		return res
+	}
+	res += "T"
+	if h := d / time.Hour; h > 0 {
+		res += fmt.Sprintf("%dH", h)
+		d -= h * time.Hour
+	}
+	if m := d / time.Minute; m > 0 {
+		res += fmt.Sprintf("%dM", m)
+		d -= m * time.Minute
+	}
+	if s := d / time.Second; s > 0 {
+		res += fmt.Sprintf("%dS", s)
+	}
+	return res
+}
+
+var icalWeekdays = []string{"SU", "MO", "TU", "WE", "TH", "FR", "SA"}
+
+func (e calendarEvent) rrule() string {
+	if e.Count < 2 {
+		return ""
+	}
+	if len(e.Weekdays) > 0 {
+		days := make([]string, 0, len(e.Weekdays))
+		for _, d := range e.Weekdays {
+			days = append(days, icalWeekdays[d])
+		}
+		return fmt.Sprintf("FREQ=WEEKLY;BYDAY=%s;COUNT=%d", strings.Join(days, ","), e.Count)
+	}
+	if e.Interval == 1 {
+		return fmt.Sprintf("FREQ=DAILY;COUNT=%d", e.Count)
+	}
+	return fmt.Sprintf("FREQ=DAILY;INTERVAL=%d;COUNT=%d", e.Interval, e.Count)
+}
+
+// icalWriter writes content lines, folded at 75 octets and terminated
+// by CRLF.
+type icalWriter struct {
+	w   io.Writer
+	err error
+}
+
+func (w *icalWriter) line(name, value string) {
+	if w.err != nil {
+		return
+	}
+	l := name + ":" + value
+	prefix := ""
+	for len(l) > 75-len(prefix) {
+		cut := 75 - len(prefix)
+		for utf8.RuneStart(l[cut]) == false {
+			cut--
+		}
+		_, w.err = io.WriteString(w.w, prefix+l[:cut]+"\r\n")
+		if w.err != nil {
+			return
+		}
+		l = l[cut:]
+		prefix = " "
+	}
+	_, w.err = io.WriteString(w.w, prefix+l+"\r\n")
+}
+
+// writeCalendar writes events as an iCalendar (RFC 5545) file.
+func writeCalendar(out io.Writer, name string, events []calendarEvent, now time.Time) error {
+	w := &icalWriter{w: out}
+	w.line("BEGIN", "VCALENDAR")
+	w.line("VERSION", "2.0")
+	w.line("PRODID", "-//formicidae-tracker//zeus-cli//EN")
+	w.line("CALSCALE", "GREGORIAN")
+	w.line("X-WR-CALNAME", escapeICalText(name))
+	for _, e := range events {
+		t := e.Transition
+		w.line("BEGIN", "VEVENT")
+		w.line("UID", fmt.Sprintf("%s-%s-%s-%s@zeus", e.Zone, t.From, t.To, formatICalTime(t.Start)))
+		w.line("DTSTAMP", formatICalTime(now))
+		w.line("DTSTART", formatICalTime(t.Start))
+		w.line("DURATION", formatICalDuration(t.Duration))
+		if rrule := e.rrule(); len(rrule) > 0 {
+			w.line("RRULE", rrule)
+		}
+		w.line("SUMMARY", escapeICalText(fmt.Sprintf("%s: %s to %s", e.Zone, t.From, t.To)))
+		w.line("DESCRIPTION", escapeICalText(fmt.Sprintf("zone '%s' transitions from '%s' to '%s' in %s", e.Zone, t.From, t.To, t.Duration)))
+		w.line("CATEGORIES", escapeICalText(e.Zone))
+		w.line("END", "VEVENT")
+	}
+	w.line("END", "VCALENDAR")
+	return w.err
+}
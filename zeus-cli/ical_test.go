@@ -0,0 +1,196 @@
+package main
+
+import (
+	"bytes"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+	"unicode/utf8"
+
+	"github.com/formicidae-tracker/zeus"
+	. "gopkg.in/check.v1"
+)
+
+// Hook up gocheck into the "go test" runner.
+func Test(t *testing.T) { TestingT(t) }
+
+type ICalSuite struct{}
+
+var _ = Suite(&ICalSuite{})
+
+// occurrences returns a transition from night to day at 06:00 UTC on
+// each day after March 1st 2021, a monday.
+func occurrences(days ...int) []zeus.TransitionDescription {
+	res := make([]zeus.TransitionDescription, 0, len(days))
+	for _, d := range days {
+		res = append(res, zeus.TransitionDescription{
+			From:     "night",
+			To:       "day",
+			Start:    time.Date(2021, 3, 1+d, 6, 0, 0, 0, time.UTC),
+			Duration: 30 * time.Minute,
+		})
+	}
+	return res
+}
+
+func (s *ICalSuite) TestRecurringEvents(c *C) {
+	type event struct {
+		Day   int
+		RRule string
+	}
+	testdata := []struct {
+		Name     string
+		Days     []int
+		Expected []event
+	}{
+		{"single", []int{3}, []event{{3, ""}}},
+		{"daily", []int{0, 1, 2, 3}, []event{{0, "FREQ=DAILY;COUNT=4"}}},
+		{"interval", []int{1, 3, 5}, []event{{1, "FREQ=DAILY;INTERVAL=2;COUNT=3"}}},
+		{"weekly", []int{0, 2, 7, 9, 14}, []event{{0, "FREQ=WEEKLY;BYDAY=MO,WE;COUNT=5"}}},
+		{"weekly unordered days", []int{5, 6, 7, 12, 13}, []event{{5, "FREQ=WEEKLY;BYDAY=SU,MO,SA;COUNT=5"}}},
+		{
+			"split runs",
+			[]int{0, 1, 2, 10, 12, 14, 30},
+			[]event{{0, "FREQ=DAILY;COUNT=3"}, {10, "FREQ=DAILY;INTERVAL=2;COUNT=3"}, {30, ""}},
+		},
+	}
+	for _, d := range testdata {
+		events := recurringEvents("box", occurrences(d.Days...))
+		if c.Check(len(events), Equals, len(d.Expected), Commentf("%s: %+v", d.Name, events)) == false {
+			continue
+		}
+		for i, e := range d.Expected {
+			c.Check(events[i].Zone, Equals, "box")
+			c.Check(events[i].Transition.Start, Equals, time.Date(2021, 3, 1+e.Day, 6, 0, 0, 0, time.UTC), Commentf(d.Name))
+			c.Check(events[i].rrule(), Equals, e.RRule, Commentf(d.Name))
+		}
+	}
+
+	// occurrences at different times or of different transitions
+	// are not grouped
+	mixed := occurrences(0, 1)
+	mixed[1].Start = mixed[1].Start.Add(time.Hour)
+	other := occurrences(2)
+	other[0].To = "storm"
+	events := recurringEvents("box", append(mixed, other...))
+	c.Assert(len(events), Equals, 3)
+	for _, e := range events {
+		c.Check(e.rrule(), Equals, "")
+	}
+}
+
+func (s *ICalSuite) TestWeeklyDays(c *C) {
+	weekdays, ok := weeklyDays(occurrences(0, 4, 7, 11))
+	c.Check(ok, Equals, true)
+	c.Check(weekdays, DeepEquals, []time.Weekday{time.Monday, time.Friday})
+	// a missing friday
+	_, ok = weeklyDays(occurrences(0, 4, 7, 14))
+	c.Check(ok, Equals, false)
+	// every weekday is a daily recurrence
+	_, ok = weeklyDays(occurrences(0, 1, 2, 3, 4, 5, 6))
+	c.Check(ok, Equals, false)
+}
+
+func (s *ICalSuite) TestFormat(c *C) {
+	testdata := []struct {
+		Duration time.Duration
+		Expected string
+	}{
+		{0, "PT0S"},
+		{90 * time.Second, "PT1M30S"},
+		{2 * time.Hour, "PT2H"},
+		{26*time.Hour + 5*time.Second, "P1DT2H5S"},
+		{48 * time.Hour, "P2D"},
+	}
+	for _, d := range testdata {
+		c.Check(formatICalDuration(d.Duration), Equals, d.Expected)
+	}
+	c.Check(escapeICalText("a, b; c\\d\ne"), Equals, `a\, b\; c\\d\ne`)
+	c.Check(formatICalTime(time.Date(2021, 3, 1, 7, 0, 0, 0, time.FixedZone("CET", 3600))), Equals, "20210301T060000Z")
+}
+
+func (s *ICalSuite) TestFolding(c *C) {
+	testdata := []string{
+		"short",
+		strings.Repeat("a", 74),
+		strings.Repeat("a", 200),
+		// 2 and 3 octets runes must not be split
+		strings.Repeat("é", 100),
+		"a" + strings.Repeat("température ☀ ", 20),
+	}
+	for _, value := range testdata {
+		buffer := bytes.NewBuffer(nil)
+		w := &icalWriter{w: buffer}
+		w.line("DESCRIPTION", value)
+		c.Assert(w.err, IsNil)
+		out := buffer.String()
+		c.Check(strings.HasSuffix(out, "\r\n"), Equals, true)
+		lines := strings.Split(strings.TrimSuffix(out, "\r\n"), "\r\n")
+		for i, l := range lines {
+			c.Check(len(l) <= 75, Equals, true, Commentf("line %d is %d octets", i, len(l)))
+			c.Check(utf8.ValidString(l), Equals, true, Commentf("line %d: %q", i, l))
+			if i > 0 {
+				c.Check(strings.HasPrefix(l, " "), Equals, true)
+			}
+		}
+		// unfolding gives back the content line
+		c.Check(strings.Replace(strings.TrimSuffix(out, "\r\n"), "\r\n ", "", -1), Equals, "DESCRIPTION:"+value)
+	}
+}
+
+type failingWriter struct {
+	remaining int
+}
+
+func (w *failingWriter) Write(p []byte) (int, error) {
+	if len(p) > w.remaining {
+		n := w.remaining
+		w.remaining = 0
+		return n, errors.New("disk full")
+	}
+	w.remaining -= len(p)
+	return len(p), nil
+}
+
+func (s *ICalSuite) TestWriteCalendar(c *C) {
+	now := time.Date(2021, 2, 28, 12, 0, 0, 0, time.UTC)
+	events := recurringEvents("box", occurrences(0, 1, 2))
+	single := occurrences(4)[0]
+	single.From, single.To, single.Duration = "day", "night, rain", 0
+	events = append(events, recurringEvents("box", []zeus.TransitionDescription{single})...)
+
+	buffer := bytes.NewBuffer(nil)
+	c.Assert(writeCalendar(buffer, "my.season", events, now), IsNil)
+	expected := strings.Join([]string{
+		"BEGIN:VCALENDAR",
+		"VERSION:2.0",
+		"PRODID:-//formicidae-tracker//zeus-cli//EN",
+		"CALSCALE:GREGORIAN",
+		"X-WR-CALNAME:my.season",
+		"BEGIN:VEVENT",
+		"UID:box-night-day-20210301T060000Z@zeus",
+		"DTSTAMP:20210228T120000Z",
+		"DTSTART:20210301T060000Z",
+		"DURATION:PT30M",
+		"RRULE:FREQ=DAILY;COUNT=3",
+		"SUMMARY:box: night to day",
+		"DESCRIPTION:zone 'box' transitions from 'night' to 'day' in 30m0s",
+		"CATEGORIES:box",
+		"END:VEVENT",
+		"BEGIN:VEVENT",
+		"UID:box-day-night, rain-20210305T060000Z@zeus",
+		"DTSTAMP:20210228T120000Z",
+		"DTSTART:20210305T060000Z",
+		"DURATION:PT0S",
+		`SUMMARY:box: day to night\, rain`,
+		`DESCRIPTION:zone 'box' transitions from 'day' to 'night\, rain' in 0s`,
+		"CATEGORIES:box",
+		"END:VEVENT",
+		"END:VCALENDAR",
+		"",
+	}, "\r\n")
+	c.Check(buffer.String(), Equals, expected)
+
+	c.Check(writeCalendar(&failingWriter{remaining: 100}, "my.season", events, now), ErrorMatches, "disk full")
+}
@@ -0,0 +1,57 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"time"
+
+	"github.com/formicidae-tracker/zeus"
+	"github.com/jessevdk/go-flags"
+)
+
+type LintCommand struct {
+	StartDate string `long:"start-date" description:"date the experiment starts like 2006-01-02, using current date if left blank"`
+
+	Args struct {
+		SeasonFiles []flags.Filename
+	} `positional-args:"yes" required:"1"`
+}
+
+func (c *LintCommand) Execute(args []string) error {
+	reference := time.Now()
+	if len(c.StartDate) > 0 {
+		var err error
+		reference, err = time.Parse("2006-01-02", c.StartDate)
+		if err != nil {
+			return err
+		}
+	}
+
+	errors := 0
+	for _, filename := range c.Args.SeasonFiles {
+		issues, err := zeus.LintSeasonFile(string(filename), reference)
+		if err != nil {
+			return err
+		}
+		for _, issue := range issues {
+			fmt.Fprintln(os.Stdout, issue)
+			if issue.Error == true {
+				errors++
+			}
+		}
+	}
+	if errors > 0 {
+		return fmt.Errorf("found %d error(s)", errors)
+	}
+	return nil
+}
+
+func init() {
+	_, err := parser.AddCommand("lint",
+		"checks season files",
+		"checks season files for common mistakes, and reports them with their position in the file. Exits with a non-zero status if any error is found",
+		&LintCommand{})
+	if err != nil {
+		panic(err.Error())
+	}
+}
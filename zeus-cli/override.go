@@ -0,0 +1,103 @@
+package main
+
+import (
+	"time"
+
+	"github.com/formicidae-tracker/zeus"
+)
+
+type OverrideCommand struct {
+	Temperature  *float64      `long:"temperature" short:"t" description:"temperature to force in °C"`
+	Humidity     *float64      `long:"humidity" short:"H" description:"humidity to force in % R.H."`
+	Wind         *float64      `long:"wind" short:"w" description:"wind to force in %"`
+	VisibleLight *float64      `long:"visible-light" short:"v" description:"visible light to force in %"`
+	UVLight      *float64      `long:"uv-light" short:"u" description:"UV light to force in %"`
+	Duration     time.Duration `long:"duration" short:"d" description:"duration of the override, like 30m or 2h" required:"true"`
+
+	Args struct {
+		Node Nodename
+		Zone string
+	} `positional-args:"yes" required:"yes"`
+}
+
+func (c *OverrideCommand) state() zeus.State {
+	res := zeus.State{
+		Temperature:  zeus.UndefinedTemperature,
+		Humidity:     zeus.UndefinedHumidity,
+		Wind:         zeus.UndefinedWind,
+		VisibleLight: zeus.UndefinedLight,
+		UVLight:      zeus.UndefinedLight,
+	}
+	if c.Temperature != nil {
+		res.Temperature = zeus.Temperature(*c.Temperature)
+	}
+	if c.Humidity != nil {
+		res.Humidity = zeus.Humidity(*c.Humidity)
+	}
+	if c.Wind != nil {
+		res.Wind = zeus.Wind(*c.Wind)
+	}
+	if c.VisibleLight != nil {
+		res.VisibleLight = zeus.Light(*c.VisibleLight)
+	}
+	if c.UVLight != nil {
+		res.UVLight = zeus.Light(*c.UVLight)
+	}
+	return res
+}
+
+func (c *OverrideCommand) Execute(args []string) error {
+	node, err := GetNode(c.Args.Node)
+	if err != nil {
+		return err
+	}
+	unused := 0
+	return node.RunMethod("Zeus.OverrideZone",
+		zeus.ZeusOverrideArgs{
+			ZoneName: c.Args.Zone,
+			State:    c.state(),
+			Duration: c.Duration,
+		}, &unused)
+}
+
+type ClearOverrideCommand struct {
+	Args struct {
+		Node Nodename
+		Zone string
+	} `positional-args:"yes" required:"yes"`
+}
+
+func (c *ClearOverrideCommand) Execute(args []string) error {
+	node, err := GetNode(c.Args.Node)
+	if err != nil {
+		return err
+	}
+	unused := 0
+	return node.RunMethod("Zeus.ClearOverride", c.Args.Zone, &unused)
+}
+
+func init() {
+	override, err := parser.AddCommand("override",
+		"manually overrides the climate of a zone",
+		"forces some climate values of a zone for a given duration, then resumes its schedule",
+		&struct{}{})
+	if err != nil {
+		panic(err.Error())
+	}
+
+	_, err = override.AddCommand("set",
+		"forces climate values of a zone",
+		"forces the given climate values of a zone for a duration. Values left unspecified follow the schedule",
+		&OverrideCommand{})
+	if err != nil {
+		panic(err.Error())
+	}
+
+	_, err = override.AddCommand("clear",
+		"clears a zone override",
+		"clears the override of a zone, which resumes its schedule immediately",
+		&ClearOverrideCommand{})
+	if err != nil {
+		panic(err.Error())
+	}
+}
@@ -66,6 +66,19 @@ func (c *ScanCommand) Execute(args []string) error {
 		for n, s := range status.Zones {
 			line.Zone = node.Name + "." + n
 			line.Status = fmt.Sprintf("'%s' %.2f / %.2f °C %.2f / %.2f %% R.H.", s.State.Name, s.Temperature, s.State.Temperature, s.Humidity, s.State.Humidity)
+			if t := s.State.HumidityTarget; t != nil && t.AbsoluteHumidity > 0 {
+				line.Status += fmt.Sprintf(" %.2f / %.2f g/m³", s.AbsoluteHumidity, t.AbsoluteHumidity)
+			} else if t != nil {
+				line.Status += fmt.Sprintf(" %.2f / %.2f kPa VPD", s.VaporPressureDeficit, t.VaporPressureDeficit)
+			}
+			if s.Ended == true {
+				line.Status = fmt.Sprintf("Ended at %s, '%s'", s.End.Format("Jan 02 15:04"), s.State.Name)
+			} else if s.End != nil {
+				line.Status += fmt.Sprintf(" (ends in %s)", s.End.Sub(now).Truncate(time.Minute))
+			}
+			if s.Override != nil {
+				line.Status += fmt.Sprintf(" (overridden until %s)", s.Override.Until.Format("15:04"))
+			}
 
 			lines = append(lines, line)
 		}
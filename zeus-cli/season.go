@@ -0,0 +1,90 @@
+package main
+
+import (
+	"fmt"
+	"io/ioutil"
+	"os"
+
+	"github.com/formicidae-tracker/zeus"
+	"github.com/jessevdk/go-flags"
+	yaml "gopkg.in/yaml.v2"
+)
+
+type ExpandCommand struct {
+	Args struct {
+		SeasonFile flags.Filename
+	} `positional-args:"yes" required:"yes"`
+}
+
+func (c *ExpandCommand) Execute(args []string) error {
+	season, err := zeus.ReadSeasonFile(string(c.Args.SeasonFile), os.Stderr)
+	if err != nil {
+		return err
+	}
+	data, err := yaml.Marshal(season)
+	if err != nil {
+		return err
+	}
+	fmt.Printf("%s", data)
+	return nil
+}
+
+type MigrateCommand struct {
+	Stdout bool `long:"stdout" short:"o" description:"prints the migrated file on stdout instead of rewriting it"`
+	Args   struct {
+		SeasonFile flags.Filename
+	} `positional-args:"yes" required:"yes"`
+}
+
+func (c *MigrateCommand) Execute(args []string) error {
+	filename := string(c.Args.SeasonFile)
+	data, err := ioutil.ReadFile(filename)
+	if err != nil {
+		return err
+	}
+	migrated, removed, err := zeus.MigrateSeason(data)
+	if err != nil {
+		return fmt.Errorf("%s: %s", filename, err)
+	}
+	for _, key := range removed {
+		fmt.Fprintf(os.Stderr, "%s: removed deprecated '%s'\n", filename, key)
+	}
+	if c.Stdout == true {
+		fmt.Printf("%s", migrated)
+		return nil
+	}
+	info, err := os.Stat(filename)
+	if err != nil {
+		return err
+	}
+	return ioutil.WriteFile(filename, migrated, info.Mode())
+}
+
+var seasonCommand *flags.Command
+
+func init() {
+	var err error
+	seasonCommand, err = parser.AddCommand("season",
+		"season file utilities",
+		"utilities to work with season files",
+		&struct{}{})
+	if err != nil {
+		panic(err.Error())
+	}
+
+	_, err = seasonCommand.AddCommand("expand",
+		"prints an expanded season file",
+		"prints a season file with all its includes, templates and state inheritance expanded, as it is sent to nodes",
+		&ExpandCommand{})
+	if err != nil {
+		panic(err.Error())
+	}
+
+	_, err = seasonCommand.AddCommand("migrate",
+		"migrates a season file to the latest format",
+		"rewrites a season file to the latest format version, removing deprecated keys but keeping comments. Included files should be migrated separately",
+		&MigrateCommand{})
+	if err != nil {
+		panic(err.Error())
+	}
+}
@@ -3,6 +3,7 @@ package main
 import (
 	"fmt"
 	"os"
+	"sort"
 	"time"
 
 	"github.com/formicidae-tracker/zeus"
@@ -10,8 +11,12 @@ import (
 )
 
 type SimulateCommand struct {
-	StartTime string `long:"start-time" short:"s" description:"starting hours and minutes of the simulation like 15:04, using current time if left blank"`
-	Duration  int    `long:"duration" short:"d" description:"length of the simulation in days" default:"7"`
+	StartDate string        `long:"start-date" description:"starting date of the simulation like 2006-01-02, using current date if left blank"`
+	StartTime string        `long:"start-time" short:"s" description:"starting hours and minutes of the simulation like 15:04, using current time if left blank"`
+	Duration  int           `long:"duration" short:"d" description:"length of the simulation in days" default:"7"`
+	Format    string        `long:"format" short:"f" description:"output format" choice:"text" choice:"csv" choice:"json" default:"text"`
+	Period    time.Duration `long:"period" short:"p" description:"sampling period of csv, json and svg outputs" default:"10m"`
+	SVG       string        `long:"svg" description:"draws the simulated schedule in this SVG file"`
 
 	Args struct {
 		SeasonFile flags.Filename
@@ -25,49 +30,127 @@ func (c *SimulateCommand) Execute(args []string) error {
 	if err != nil {
 		return err
 	}
+	season.Zones, err = season.ResolveZones()
+	if err != nil {
+		return err
+	}
 
-	var start time.Time
-	if len(c.StartTime) == 0 {
-		start = time.Now()
-	} else {
-		var err error
-		start, err = time.Parse("15:04", c.StartTime)
-		if err != nil {
-			return err
-		}
-		y, m, d := time.Now().Date()
-		start = start.AddDate(y, int(m), d)
+	start, err := c.start(time.Now())
+	if err != nil {
+		return err
+	}
+
+	if len(c.SVG) > 0 {
+		return c.draw(season, start)
+	}
+
+	if c.Format != "text" {
+		return c.export(season, start)
 	}
 
 	for name, zone := range season.Zones {
 		fmt.Printf("=== Simulating zone '%s' for %d day from %s ===\n", name, c.Duration, start.Format("Mon Jan 02 15:04:05 -0700 MST 2006"))
 
-		i, err := zeus.NewClimateInterpoler(zone.States, zone.Transitions, start.UTC())
+		i, err := zeus.NewZoneClimateInterpoler(zone, start.UTC())
 		if err != nil {
 			return err
 		}
-		var t time.Time
-		for t = start; t.Before(start.AddDate(0, 0, c.Duration)); {
-			toTest := t.Add(1 * time.Second)
-			inter, next, nextInterpolation := i.CurrentInterpolation(toTest)
-			fmt.Printf("%s state is %s\n", t.Local().Format("Mon Jan 02 15:04:05 -0700 MST 2006"), inter)
-			if nextInterpolation == nil {
-				fmt.Printf("No more transition\n")
-				t = start.AddDate(0, 0, c.Duration)
-				continue
-			}
-			t = next
+		location, err := zone.Location()
+		if err != nil {
+			return err
+		}
+		if len(zone.Timezone) == 0 {
+			location = time.Local
+		}
+		end := start.AddDate(0, 0, c.Duration)
+		for _, s := range zeus.Timeline(i, start, end) {
+			fmt.Printf("%s state is %s\n", s.Start.In(location).Format("Mon Jan 02 15:04:05 -0700 MST 2006"), s.Interpolation)
 		}
-		fmt.Printf("=== End of simulation at %s ===\n", t.Format("Mon Jan 02 15:04:05 -0700 MST 2006"))
+		fmt.Printf("=== End of simulation at %s ===\n", end.In(location).Format("Mon Jan 02 15:04:05 -0700 MST 2006"))
 	}
 
 	return nil
 }
 
+// start returns the start of the simulation. Its date and time
+// default to now.
+func (c *SimulateCommand) start(now time.Time) (time.Time, error) {
+	y, m, d := now.Date()
+	if len(c.StartDate) > 0 {
+		date, err := time.Parse("2006-01-02", c.StartDate)
+		if err != nil {
+			return time.Time{}, err
+		}
+		y, m, d = date.Date()
+		if len(c.StartTime) == 0 {
+			return time.Date(y, m, d, 0, 0, 0, 0, now.Location()), nil
+		}
+	}
+	if len(c.StartTime) == 0 {
+		return time.Date(y, m, d, now.Hour(), now.Minute(), now.Second(), now.Nanosecond(), now.Location()), nil
+	}
+	t, err := time.Parse("15:04", c.StartTime)
+	if err != nil {
+		return time.Time{}, err
+	}
+	return time.Date(y, m, d, t.Hour(), t.Minute(), 0, 0, now.Location()), nil
+}
+
+func (c *SimulateCommand) samples(season *zeus.SeasonFile, start time.Time) ([]timelineSample, error) {
+	names := make([]string, 0, len(season.Zones))
+	for name := range season.Zones {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	var res []timelineSample
+	for _, name := range names {
+		samples, err := sampleTimeline(name, season.Zones[name], start, time.Duration(c.Duration)*24*time.Hour, c.Period)
+		if err != nil {
+			return nil, fmt.Errorf("zone '%s': %s", name, err)
+		}
+		res = append(res, samples...)
+	}
+	return res, nil
+}
+
+func (c *SimulateCommand) export(season *zeus.SeasonFile, start time.Time) error {
+	samples, err := c.samples(season, start)
+	if err != nil {
+		return err
+	}
+	if c.Format == "csv" {
+		return writeTimelineCSV(os.Stdout, samples)
+	}
+	return writeTimelineJSON(os.Stdout, samples)
+}
+
+func (c *SimulateCommand) draw(season *zeus.SeasonFile, start time.Time) error {
+	samples, err := c.samples(season, start)
+	if err != nil {
+		return err
+	}
+	var zones []svgZone
+	for _, s := range samples {
+		if len(zones) == 0 || zones[len(zones)-1].Name != s.Zone {
+			zones = append(zones, svgZone{Name: s.Zone, Climate: season.Zones[s.Zone]})
+		}
+		zones[len(zones)-1].Samples = append(zones[len(zones)-1].Samples, s)
+	}
+	f, err := os.Create(c.SVG)
+	if err != nil {
+		return err
+	}
+	if err := writeTimelineSVG(f, zones, start, time.Duration(c.Duration)*24*time.Hour); err != nil {
+		f.Close()
+		return err
+	}
+	return f.Close()
+}
+
 func init() {
 	_, err := parser.AddCommand("simulate",
 		"simulate a season file",
-		"simulate a season file, and displays climate states and transitions on stdout",
+		"simulate a season file, and displays climate states and transitions on stdout, or samples them as csv or json",
 		simulateCommand)
 	if err != nil {
 		panic(err.Error())
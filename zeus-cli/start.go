@@ -1,13 +1,91 @@
 package main
 
 import (
+	"fmt"
+	"io"
 	"os"
+	"sort"
+	"strings"
+	"time"
 
 	"github.com/formicidae-tracker/zeus"
 	"github.com/jessevdk/go-flags"
 )
 
+// checkHardwareLimits rejects a season which asks for values the zones
+// of node cannot reach.
+func checkHardwareLimits(node Node, season *zeus.SeasonFile) error {
+	status := zeus.ZeusStatusReply{}
+	ignored := 0
+	if err := node.RunMethod("Zeus.Status", ignored, &status); err != nil {
+		return err
+	}
+	zones, err := season.ResolveZones()
+	if err != nil {
+		return err
+	}
+	for name, climate := range zones {
+		// older nodes do not report their limits
+		limits, ok := status.Limits[name]
+		if ok == false {
+			continue
+		}
+		if err := limits.CheckClimate(climate); err != nil {
+			return fmt.Errorf("node '%s' cannot run zone '%s': %s", node.Name, name, err)
+		}
+	}
+	return nil
+}
+
+// heartbeatStatus describes if a device answers heartbeat requests.
+func heartbeatStatus(d zeus.ZeusDeviceStatus, now time.Time) string {
+	last := ""
+	if d.LastHeartbeat != nil {
+		last = fmt.Sprintf("last heartbeat %s ago", now.Sub(*d.LastHeartbeat).Truncate(time.Second))
+	}
+	if d.Monitored == false {
+		if len(last) == 0 {
+			return "unknown, interface is not open"
+		}
+		return fmt.Sprintf("unknown, interface is not open (%s)", last)
+	}
+	if d.LastHeartbeat == nil {
+		return "not answering"
+	}
+	ellapsed := now.Sub(*d.LastHeartbeat).Truncate(time.Second)
+	if ellapsed > 3*zeus.HeartBeatPeriod {
+		return fmt.Sprintf("not answering since %s", ellapsed)
+	}
+	return fmt.Sprintf("answering (%s)", last)
+}
+
+// printValidation writes how node would start a season.
+func printValidation(w io.Writer, node Node, v zeus.ZeusValidationReply) {
+	now := time.Now()
+	names := make([]string, 0, len(v.Zones))
+	for name := range v.Zones {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		zone := v.Zones[name]
+		fmt.Fprintf(w, "zone '%s.%s' on %s:\n", node.Name, name, zone.Interface)
+		fmt.Fprintf(w, "  capabilities: %s\n", strings.Join(zone.Capabilities, ", "))
+		for _, d := range zone.Devices {
+			fmt.Fprintf(w, "  device %s.%d: %s\n", d.Class, d.ID, heartbeatStatus(d, now))
+		}
+		if len(zone.Olympus) > 0 {
+			fmt.Fprintf(w, "  reports to olympus at %s\n", zone.Olympus)
+		}
+	}
+	if len(v.SlackUser) > 0 {
+		fmt.Fprintf(w, "notifies slack user %s (%s)\n", v.SlackUser, v.SlackUserID)
+	}
+}
+
 type StartCommand struct {
+	DryRun bool `long:"dry-run" short:"n" description:"only reports how the node would start the season"`
+
 	Args struct {
 		Node       Nodename
 		SeasonFile flags.Filename
@@ -23,6 +101,25 @@ func (c *StartCommand) Execute(args []string) error {
 	if err != nil {
 		return err
 	}
+	if err := checkHardwareLimits(node, season); err != nil {
+		return err
+	}
+	if c.DryRun == true {
+		validation := zeus.ZeusValidationReply{}
+		err := node.RunMethod("Zeus.ValidateSeason",
+			zeus.ZeusStartArgs{
+				Version: zeus.ZEUS_VERSION,
+				Season:  *season,
+			}, &validation)
+		if err != nil {
+			return err
+		}
+		printValidation(os.Stdout, node, validation)
+		if len(validation.Errors) > 0 {
+			return fmt.Errorf("season would not start on '%s':\n%s", node.Name, strings.Join(validation.Errors, "\n"))
+		}
+		return nil
+	}
 	unused := 0
 	return node.RunMethod("Zeus.StartClimate",
 		zeus.ZeusStartArgs{
@@ -31,6 +128,33 @@ func (c *StartCommand) Execute(args []string) error {
 		}, &unused)
 }
 
+type UpdateCommand struct {
+	Args struct {
+		Node       Nodename
+		SeasonFile flags.Filename
+	} `positional-args:"yes" required:"yes"`
+}
+
+func (c *UpdateCommand) Execute(args []string) error {
+	season, err := zeus.ReadSeasonFile(string(c.Args.SeasonFile), os.Stderr)
+	if err != nil {
+		return err
+	}
+	node, err := GetNode(c.Args.Node)
+	if err != nil {
+		return err
+	}
+	if err := checkHardwareLimits(node, season); err != nil {
+		return err
+	}
+	unused := 0
+	return node.RunMethod("Zeus.UpdateClimate",
+		zeus.ZeusStartArgs{
+			Version: zeus.ZEUS_VERSION,
+			Season:  *season,
+		}, &unused)
+}
+
 type StopCommand struct {
 	Args struct {
 		Node Nodename
@@ -55,6 +179,14 @@ func init() {
 		panic(err.Error())
 	}
 
+	_, err = parser.AddCommand("update",
+		"updates the climate running on node",
+		"replaces the season running on a specified node, without stopping climate control nor resetting the experiment day count",
+		&UpdateCommand{})
+	if err != nil {
+		panic(err.Error())
+	}
+
 	_, err = parser.AddCommand("stop",
 		"stops climate on node",
 		"stops climate on a specified node",
@@ -0,0 +1,39 @@
+package main
+
+import (
+	"time"
+
+	"github.com/formicidae-tracker/libarke/src-go/arke"
+	"github.com/formicidae-tracker/zeus"
+	. "gopkg.in/check.v1"
+)
+
+type StartSuite struct{}
+
+var _ = Suite(&StartSuite{})
+
+func (s *StartSuite) TestHeartbeatStatus(c *C) {
+	now := time.Date(2021, 3, 1, 12, 0, 0, 0, time.UTC)
+	recent := now.Add(-3 * time.Second)
+	old := now.Add(-10 * time.Minute)
+	testdata := []struct {
+		Monitored     bool
+		LastHeartbeat *time.Time
+		Expected      string
+	}{
+		{false, nil, "unknown, interface is not open"},
+		{false, &old, "unknown, interface is not open (last heartbeat 10m0s ago)"},
+		{true, nil, "not answering"},
+		{true, &old, "not answering since 10m0s"},
+		{true, &recent, "answering (last heartbeat 3s ago)"},
+	}
+	for _, d := range testdata {
+		status := zeus.ZeusDeviceStatus{
+			Class:         arke.ZeusClass,
+			ID:            1,
+			Monitored:     d.Monitored,
+			LastHeartbeat: d.LastHeartbeat,
+		}
+		c.Check(heartbeatStatus(status, now), Equals, d.Expected)
+	}
+}
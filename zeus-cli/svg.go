@@ -0,0 +1,291 @@
+package main
+
+import (
+	"fmt"
+	"html"
+	"io"
+	"strings"
+	"time"
+
+	"github.com/formicidae-tracker/zeus"
+)
+
+// svgZone is the simulated schedule of a zone to draw.
+type svgZone struct {
+	Name    string
+	Climate zeus.ZoneClimate
+	Samples []timelineSample
+}
+
+// svgChannel is a climate value drawn in its own panel.
+type svgChannel struct {
+	Label    string
+	Color    string
+	Unit     zeus.BoundedUnit
+	Value    func(s zeus.State) zeus.BoundedUnit
+	Min, Max func(c zeus.ZoneClimate) zeus.BoundedUnit
+}
+
+var svgChannels = []svgChannel{
+	{
+		Label: "Temperature (°C)",
+		Color: "#d62728",
+		Unit:  zeus.Temperature(0),
+		Value: func(s zeus.State) zeus.BoundedUnit { return s.Temperature },
+		Min:   func(c zeus.ZoneClimate) zeus.BoundedUnit { return c.MinimalTemperature },
+		Max:   func(c zeus.ZoneClimate) zeus.BoundedUnit { return c.MaximalTemperature },
+	},
+	{
+		Label: "Humidity (% R.H.)",
+		Color: "#1f77b4",
+		Unit:  zeus.Humidity(0),
+		Value: func(s zeus.State) zeus.BoundedUnit { return s.Humidity },
+		Min:   func(c zeus.ZoneClimate) zeus.BoundedUnit { return c.MinimalHumidity },
+		Max:   func(c zeus.ZoneClimate) zeus.BoundedUnit { return c.MaximalHumidity },
+	},
+	{
+		Label: "Wind (%)",
+		Color: "#2ca02c",
+		Unit:  zeus.Wind(0),
+		Value: func(s zeus.State) zeus.BoundedUnit { return s.Wind },
+	},
+	{
+		Label: "Visible light (%)",
+		Color: "#ff7f0e",
+		Unit:  zeus.Light(0),
+		Value: func(s zeus.State) zeus.BoundedUnit { return s.VisibleLight },
+	},
+	{
+		Label: "UV light (%)",
+		Color: "#9467bd",
+		Unit:  zeus.Light(0),
+		Value: func(s zeus.State) zeus.BoundedUnit { return s.UVLight },
+	},
+}
+
+const (
+	svgWidth       = 1000.0
+	svgMarginLeft  = 70.0
+	svgMarginRight = 20.0
+	svgTitleHeight = 30.0
+	svgPanelHeight = 100.0
+	svgPanelGap    = 20.0
+	svgAxisHeight  = 30.0
+)
+
+type svgChart struct {
+	w              io.Writer
+	start          time.Time
+	duration       time.Duration
+	top, plotWidth float64
+	panelTop       float64
+	// minValue and maxValue are the range of the current panel.
+	minValue, maxValue float64
+	scale              float64
+	// err is the first write error, further writes are skipped.
+	err error
+}
+
+func (c *svgChart) printf(format string, args ...interface{}) {
+	if c.err != nil {
+		return
+	}
+	_, c.err = fmt.Fprintf(c.w, format, args...)
+}
+
+func (c *svgChart) x(t time.Time) float64 {
+	return svgMarginLeft + c.plotWidth*t.Sub(c.start).Seconds()/c.duration.Seconds()
+}
+
+func (c *svgChart) y(v float64) float64 {
+	return c.panelTop + svgPanelHeight - (v-c.minValue)*c.scale
+}
+
+// clampY returns the position of v, limited to the panel.
+func (c *svgChart) clampY(v float64) float64 {
+	if v < c.minValue {
+		v = c.minValue
+	}
+	if v > c.maxValue {
+		v = c.maxValue
+	}
+	return c.y(v)
+}
+
+// channelBounds returns the alarm bounds of channel in climate, and
+// if they are set.
+func channelBounds(channel svgChannel, climate zeus.ZoneClimate) (min, max float64, minSet, maxSet bool) {
+	if channel.Min == nil {
+		return 0, 0, false, false
+	}
+	minBound, maxBound := channel.Min(climate), channel.Max(climate)
+	// bounds left to zero are not set in the season file
+	minSet = zeus.IsUndefined(minBound) == false && minBound.Value() != 0.0
+	maxSet = zeus.IsUndefined(maxBound) == false && maxBound.Value() != 0.0
+	return minBound.Value(), maxBound.Value(), minSet, maxSet
+}
+
+// panelRange returns the range drawn for channel: the default range
+// of its unit, widened to the alarm bounds and sampled values of
+// zone.
+func panelRange(channel svgChannel, zone svgZone) (low, high float64) {
+	low, high = channel.Unit.MinValue(), channel.Unit.MaxValue()
+	widen := func(v float64) {
+		if v < low {
+			low = v
+		}
+		if v > high {
+			high = v
+		}
+	}
+	min, max, minSet, maxSet := channelBounds(channel, zone.Climate)
+	if minSet == true {
+		widen(min)
+	}
+	if maxSet == true {
+		widen(max)
+	}
+	for _, s := range zone.Samples {
+		if v := channel.Value(s.State); zeus.IsUndefined(v) == false {
+			widen(v.Value())
+		}
+	}
+	return low, high
+}
+
+func (c *svgChart) drawTransitions(samples []timelineSample) {
+	for i := 0; i < len(samples); i++ {
+		if samples[i].Transition == false {
+			continue
+		}
+		begin := samples[i].Time
+		for i < len(samples) && samples[i].Transition == true {
+			i++
+		}
+		end := c.start.Add(c.duration)
+		if i < len(samples) {
+			end = samples[i].Time
+		}
+		c.printf(`<rect x="%.1f" y="%.1f" width="%.1f" height="%.1f" fill="#000000" fill-opacity="0.08"/>`+"\n",
+			c.x(begin), c.panelTop, c.x(end)-c.x(begin), svgPanelHeight)
+	}
+}
+
+func (c *svgChart) drawBounds(channel svgChannel, climate zeus.ZoneClimate) {
+	min, max, minSet, maxSet := channelBounds(channel, climate)
+	if minSet == false && maxSet == false {
+		return
+	}
+	low, high := c.minValue, c.maxValue
+	if minSet == true {
+		low = min
+	}
+	if maxSet == true {
+		high = max
+	}
+	yLow, yHigh := c.clampY(low), c.clampY(high)
+	c.printf(`<rect x="%.1f" y="%.1f" width="%.1f" height="%.1f" fill="%s" fill-opacity="0.12"/>`+"\n",
+		svgMarginLeft, yHigh, c.plotWidth, yLow-yHigh, channel.Color)
+	for _, bound := range []struct {
+		set bool
+		y   float64
+	}{{minSet, yLow}, {maxSet, yHigh}} {
+		if bound.set == false {
+			continue
+		}
+		c.printf(`<line x1="%.1f" y1="%.1f" x2="%.1f" y2="%.1f" stroke="%s" stroke-dasharray="4 3" stroke-width="1"/>`+"\n",
+			svgMarginLeft, bound.y, svgMarginLeft+c.plotWidth, bound.y, channel.Color)
+	}
+}
+
+func (c *svgChart) drawValues(channel svgChannel, samples []timelineSample) {
+	var points []string
+	flush := func() {
+		if len(points) > 0 {
+			c.printf(`<polyline points="%s" fill="none" stroke="%s" stroke-width="1.5"/>`+"\n", strings.Join(points, " "), channel.Color)
+		}
+		points = nil
+	}
+	for _, s := range samples {
+		v := channel.Value(s.State)
+		if zeus.IsUndefined(v) == true {
+			flush()
+			continue
+		}
+		points = append(points, fmt.Sprintf("%.1f,%.1f", c.x(s.Time), c.clampY(v.Value())))
+	}
+	flush()
+}
+
+func (c *svgChart) drawPanel(channel svgChannel, zone svgZone) {
+	c.minValue, c.maxValue = panelRange(channel, zone)
+	c.scale = svgPanelHeight / (c.maxValue - c.minValue)
+
+	c.printf(`<rect x="%.1f" y="%.1f" width="%.1f" height="%.1f" fill="none" stroke="#999999"/>`+"\n",
+		svgMarginLeft, c.panelTop, c.plotWidth, svgPanelHeight)
+	c.drawTransitions(zone.Samples)
+	c.drawBounds(channel, zone.Climate)
+	c.drawValues(channel, zone.Samples)
+
+	for _, v := range []float64{c.minValue, (c.minValue + c.maxValue) / 2, c.maxValue} {
+		c.printf(`<text x="%.1f" y="%.1f" font-size="10" text-anchor="end" dominant-baseline="middle">%g</text>`+"\n",
+			svgMarginLeft-5, c.y(v), v)
+	}
+	c.printf(`<text x="%.1f" y="%.1f" font-size="11" text-anchor="middle" transform="rotate(-90 %.1f %.1f)">%s</text>`+"\n",
+		15.0, c.panelTop+svgPanelHeight/2, 15.0, c.panelTop+svgPanelHeight/2, html.EscapeString(channel.Label))
+}
+
+// drawDays draws a vertical line and a label at each midnight in the
+// zone timezone.
+func (c *svgChart) drawDays(location *time.Location, bottom float64) {
+	start := c.start.In(location)
+	day := time.Date(start.Year(), start.Month(), start.Day(), 0, 0, 0, 0, location)
+	for ; day.Before(c.start.Add(c.duration)); day = day.AddDate(0, 0, 1) {
+		if day.Before(c.start) == true {
+			continue
+		}
+		c.printf(`<line x1="%.1f" y1="%.1f" x2="%.1f" y2="%.1f" stroke="#cccccc" stroke-width="1"/>`+"\n",
+			c.x(day), c.top+svgTitleHeight, c.x(day), bottom)
+		c.printf(`<text x="%.1f" y="%.1f" font-size="10" text-anchor="middle">%s</text>`+"\n",
+			c.x(day), bottom+15, day.Format("Jan 02"))
+	}
+}
+
+func zoneSVGHeight() float64 {
+	return svgTitleHeight + float64(len(svgChannels))*(svgPanelHeight+svgPanelGap) + svgAxisHeight
+}
+
+// writeTimelineSVG draws the sampled schedule of zones, from start
+// for duration.
+func writeTimelineSVG(w io.Writer, zones []svgZone, start time.Time, duration time.Duration) error {
+	if duration <= 0 {
+		return fmt.Errorf("invalid duration %s: should be positive", duration)
+	}
+	height := float64(len(zones)) * zoneSVGHeight()
+	c := &svgChart{
+		w:         w,
+		start:     start,
+		duration:  duration,
+		plotWidth: svgWidth - svgMarginLeft - svgMarginRight,
+	}
+	c.printf(`<?xml version="1.0" encoding="UTF-8"?>` + "\n")
+	c.printf(`<svg xmlns="http://www.w3.org/2000/svg" width="%.0f" height="%.0f" viewBox="0 0 %.0f %.0f" font-family="sans-serif">`+"\n",
+		svgWidth, height, svgWidth, height)
+	c.printf(`<rect width="100%%" height="100%%" fill="#ffffff"/>` + "\n")
+	for i, zone := range zones {
+		c.top = float64(i) * zoneSVGHeight()
+		location := time.Local
+		if len(zone.Samples) > 0 {
+			location = zone.Samples[0].Time.Location()
+		}
+		c.printf(`<text x="%.1f" y="%.1f" font-size="14" font-weight="bold">zone '%s' from %s</text>`+"\n",
+			svgMarginLeft, c.top+20, html.EscapeString(zone.Name), start.In(location).Format("Mon Jan 02 15:04 MST 2006"))
+		for j, channel := range svgChannels {
+			c.panelTop = c.top + svgTitleHeight + float64(j)*(svgPanelHeight+svgPanelGap)
+			c.drawPanel(channel, zone)
+		}
+		c.drawDays(location, c.panelTop+svgPanelHeight)
+	}
+	c.printf("</svg>\n")
+	return c.err
+}
@@ -0,0 +1,59 @@
+package main
+
+import (
+	"bytes"
+	"strings"
+	"time"
+
+	"github.com/formicidae-tracker/zeus"
+	. "gopkg.in/check.v1"
+)
+
+type SVGSuite struct{}
+
+var _ = Suite(&SVGSuite{})
+
+func (s *SVGSuite) TestPanelRange(c *C) {
+	start := time.Date(2021, 3, 1, 0, 0, 0, 0, time.UTC)
+	state := zeus.State{
+		Name:         "cold",
+		Temperature:  8,
+		Humidity:     zeus.UndefinedHumidity,
+		Wind:         zeus.UndefinedWind,
+		VisibleLight: zeus.UndefinedLight,
+		UVLight:      zeus.UndefinedLight,
+	}
+	zone := svgZone{
+		Name: "box",
+		Climate: zeus.ZoneClimate{
+			MinimalTemperature: 5,
+			MaximalTemperature: 45,
+			MinimalHumidity:    zeus.UndefinedHumidity,
+			MaximalHumidity:    zeus.UndefinedHumidity,
+		},
+		Samples: []timelineSample{
+			{Zone: "box", Time: start, State: state},
+			{Zone: "box", Time: start.Add(time.Hour), State: state},
+		},
+	}
+	temperature, humidity := svgChannels[0], svgChannels[1]
+
+	low, high := panelRange(temperature, zone)
+	c.Check(low, Equals, 5.0)
+	c.Check(high, Equals, 45.0)
+	low, high = panelRange(humidity, zone)
+	c.Check(low, Equals, zeus.Humidity(0).MinValue())
+	c.Check(high, Equals, zeus.Humidity(0).MaxValue())
+
+	// the value is drawn at 8°C, not at the bottom of the
+	// temperature default range
+	chart := &svgChart{w: bytes.NewBuffer(nil), start: start, duration: time.Hour, plotWidth: 100}
+	chart.minValue, chart.maxValue = 5, 45
+	chart.scale = svgPanelHeight / 40
+	c.Check(chart.clampY(8), Equals, svgPanelHeight-3*chart.scale)
+	c.Check(chart.clampY(0), Equals, svgPanelHeight)
+
+	buffer := bytes.NewBuffer(nil)
+	c.Assert(writeTimelineSVG(buffer, []svgZone{zone}, start, time.Hour), IsNil)
+	c.Check(strings.Contains(buffer.String(), `<polyline points="70.0,122.5 980.0,122.5"`), Equals, true, Commentf("%s", buffer.String()))
+}
@@ -0,0 +1,134 @@
+package main
+
+import (
+	"bytes"
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"strconv"
+	"time"
+
+	"github.com/formicidae-tracker/zeus"
+)
+
+// timelineSample is the interpolated State of a zone at a given time.
+type timelineSample struct {
+	Zone  string
+	Time  time.Time
+	State zeus.State
+	// Transition is true if the zone is transitioning between two
+	// states.
+	Transition bool
+}
+
+// sampleTimeline samples the State of a zone every period, from start
+// for duration.
+func sampleTimeline(name string, zone zeus.ZoneClimate, start time.Time, duration, period time.Duration) ([]timelineSample, error) {
+	if period <= 0 {
+		return nil, fmt.Errorf("invalid sampling period %s: should be positive", period)
+	}
+	i, err := zeus.NewZoneClimateInterpoler(zone, start.UTC())
+	if err != nil {
+		return nil, err
+	}
+	location, err := zone.Location()
+	if err != nil {
+		return nil, err
+	}
+	if len(zone.Timezone) == 0 {
+		location = time.Local
+	}
+	res := make([]timelineSample, 0, int(duration/period)+1)
+	for t := start; t.Before(start.Add(duration)); t = t.Add(period) {
+		current, _, _ := i.CurrentInterpolation(t)
+		state := current.State(t)
+		state.Humidity = state.EffectiveHumidity()
+		res = append(res, timelineSample{
+			Zone:       name,
+			Time:       t.In(location),
+			State:      state,
+			Transition: current.End() != nil,
+		})
+	}
+	return res, nil
+}
+
+func formatUnit(u zeus.BoundedUnit) string {
+	if zeus.IsUndefined(u) == true {
+		return ""
+	}
+	return strconv.FormatFloat(u.Value(), 'f', -1, 64)
+}
+
+func writeTimelineCSV(w io.Writer, samples []timelineSample) error {
+	writer := csv.NewWriter(w)
+	header := []string{"zone", "time", "name"}
+	for _, c := range zeus.Channels() {
+		header = append(header, c.Name)
+	}
+	writer.Write(header)
+	for _, s := range samples {
+		line := []string{s.Zone, s.Time.Format(time.RFC3339), s.State.Name}
+		for _, c := range zeus.Channels() {
+			line = append(line, formatUnit(c.Value(s.State)))
+		}
+		writer.Write(line)
+	}
+	writer.Flush()
+	return writer.Error()
+}
+
+// jsonSample is a timelineSample with one key per channel, in the
+// order of the channel registry.
+type jsonSample timelineSample
+
+func jsonUnit(u zeus.BoundedUnit) *float64 {
+	if zeus.IsUndefined(u) == true {
+		return nil
+	}
+	v := u.Value()
+	return &v
+}
+
+type jsonField struct {
+	key   string
+	value interface{}
+}
+
+func (s jsonSample) MarshalJSON() ([]byte, error) {
+	fields := []jsonField{
+		{"zone", s.Zone},
+		{"time", s.Time},
+		{"name", s.State.Name},
+	}
+	for _, c := range zeus.Channels() {
+		fields = append(fields, jsonField{c.Name, jsonUnit(c.Value(s.State))})
+	}
+	buffer := bytes.NewBufferString("{")
+	for i, f := range fields {
+		if i > 0 {
+			buffer.WriteString(",")
+		}
+		key, _ := json.Marshal(f.key)
+		value, err := json.Marshal(f.value)
+		if err != nil {
+			return nil, err
+		}
+		buffer.Write(key)
+		buffer.WriteString(":")
+		buffer.Write(value)
+	}
+	buffer.WriteString("}")
+	return buffer.Bytes(), nil
+}
+
+func writeTimelineJSON(w io.Writer, samples []timelineSample) error {
+	res := make([]jsonSample, 0, len(samples))
+	for _, s := range samples {
+		res = append(res, jsonSample(s))
+	}
+	encoder := json.NewEncoder(w)
+	encoder.SetIndent("", "  ")
+	return encoder.Encode(res)
+}
@@ -137,11 +137,17 @@ func (m *alarmMonitor) Monitor() {
 				return
 			}
 			if _, ok := alarms[a.Reason()]; ok == false {
+				// a delayed alarm is ignored until its cause
+				// persisted long enough.
+				if d, ok := a.(zeus.DelayedAlarm); ok == true && time.Since(d.Since()) < d.Delay() {
+					continue
+				}
 				go func() {
 					m.outbound <- zeus.AlarmEvent{
 						Reason:         a.Reason(),
 						Flags:          a.Flags(),
 						Status:         zeus.AlarmOn,
+						Description:    describeAlarm(a),
 						Time:           time.Now(),
 						ZoneIdentifier: m.name,
 					}
@@ -169,6 +175,7 @@ func (m *alarmMonitor) Monitor() {
 						Reason:         a.Reason(),
 						Flags:          a.Flags(),
 						Status:         zeus.AlarmOff,
+						Description:    describeAlarm(a),
 						Time:           now,
 						ZoneIdentifier: m.name,
 					}
@@ -179,6 +186,14 @@ func (m *alarmMonitor) Monitor() {
 	}
 }
 
+// describeAlarm returns the description of a, if it has any.
+func describeAlarm(a zeus.Alarm) string {
+	if d, ok := a.(zeus.DescribedAlarm); ok == true {
+		return d.Description()
+	}
+	return ""
+}
+
 func (m *alarmMonitor) Inbound() chan<- zeus.Alarm {
 	return m.inbound
 }
@@ -44,6 +44,15 @@ func (a testAlarm) DeadLine() time.Duration {
 	return 5 * time.Millisecond
 }
 
+type describedTestAlarm struct {
+	testAlarm
+	description string
+}
+
+func (a describedTestAlarm) Description() string {
+	return a.description
+}
+
 func (s *AlarmMonitorSuite) TestName(c *C) {
 	testName := "test-zone"
 	m, err := NewAlarmMonitor(testName)
@@ -134,6 +143,69 @@ func (s *AlarmMonitorSuite) TestMonitor(c *C) {
 	wg.Wait()
 }
 
+func (s *AlarmMonitorSuite) TestDelayedAlarm(c *C) {
+	m, err := NewAlarmMonitor("test-zone")
+	c.Assert(err, IsNil)
+	done := make(chan struct{})
+	go func() {
+		m.Monitor()
+		close(done)
+	}()
+	defer func() {
+		close(m.Inbound())
+		<-done
+	}()
+
+	delay := 50 * time.Millisecond
+	m.Inbound() <- zeus.NewOutOfBoundAlarm(zeus.TemperatureOutOfBound, time.Now(), delay)
+	select {
+	case e := <-m.Outbound():
+		c.Fatalf("unexpected event before the alarm delay: %+v", e)
+	case <-time.After(delay / 2):
+	}
+
+	m.Inbound() <- zeus.NewOutOfBoundAlarm(zeus.TemperatureOutOfBound, time.Now().Add(-delay), delay)
+	select {
+	case e := <-m.Outbound():
+		c.Check(e.Reason, Equals, zeus.TemperatureOutOfBound.Reason())
+		c.Check(e.Status, Equals, zeus.AlarmOn)
+	case <-time.After(time.Second):
+		c.Fatalf("alarm was not raised after its delay")
+	}
+}
+
+func (s *AlarmMonitorSuite) TestDescribedAlarm(c *C) {
+	m, err := NewAlarmMonitor("test-zone")
+	c.Assert(err, IsNil)
+	done := make(chan struct{})
+	go func() {
+		m.Monitor()
+		close(done)
+	}()
+	defer func() {
+		close(m.Inbound())
+		<-done
+	}()
+
+	// the description does not change the alarm identity, the last
+	// one is reported when it clears.
+	m.Inbound() <- describedTestAlarm{"too fast", "+1.00 °C/min"}
+	m.Inbound() <- describedTestAlarm{"too fast", "+1.50 °C/min"}
+	for _, expected := range []zeus.AlarmEvent{
+		{Reason: "too fast", Status: zeus.AlarmOn, Description: "+1.00 °C/min"},
+		{Reason: "too fast", Status: zeus.AlarmOff, Description: "+1.50 °C/min"},
+	} {
+		select {
+		case e := <-m.Outbound():
+			c.Check(e.Reason, Equals, expected.Reason)
+			c.Check(e.Status, Equals, expected.Status)
+			c.Check(e.Description, Equals, expected.Description)
+		case <-time.After(time.Second):
+			c.Fatalf("missing event %+v", expected)
+		}
+	}
+}
+
 func (s *AlarmMonitorSuite) TestReadAlarmLogFile(c *C) {
 	testdata := [][]zeus.AlarmEvent{
 		nil,
@@ -47,6 +47,19 @@ func NewFileAlarmReporter(filename string) (AlarmReporter, error) {
 	}, nil
 }
 
+// ResumeFileAlarmReporter appends events to an existing alarm log, or
+// creates it if needed.
+func ResumeFileAlarmReporter(filename string) (AlarmReporter, error) {
+	file, err := os.OpenFile(filename, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0644)
+	if err != nil {
+		return nil, err
+	}
+	return &fileAlarmReporter{
+		file:   file,
+		events: make(chan zeus.AlarmEvent),
+	}, nil
+}
+
 func ReadAlarmLogFile(filename string) ([]zeus.AlarmEvent, error) {
 	f, err := os.Open(filename)
 	if err != nil {
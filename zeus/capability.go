@@ -2,6 +2,8 @@ package main
 
 import (
 	"fmt"
+	"math"
+	"sync"
 	"time"
 
 	"github.com/formicidae-tracker/libarke/src-go/arke"
@@ -12,6 +14,8 @@ type callback func(c chan<- zeus.Alarm, m *StampedMessage) error
 
 type capability interface {
 	Requirements() []arke.NodeClass
+	// Channels returns the climate channels the capability drives.
+	Channels() []*zeus.Channel
 	SetDevices(devices map[arke.NodeClass]*Device)
 	Action(s zeus.State) error
 	Callbacks() map[arke.MessageClass]callback
@@ -20,15 +24,23 @@ type capability interface {
 
 type ClimateControllable struct {
 	withCelaeno       bool
+	limits            zeus.HardwareLimits
 	lastSetPoint      *arke.ZeusSetPoint
 	celaeno           *Device
 	zeus              *Device
 	celaenoResetGuard time.Time
 	zeusResetGuard    time.Time
+
+	// mx protects lastSetPoint and measured, as callbacks may run
+	// concurrently with Action.
+	mx sync.Mutex
+	// measured is the last reported temperature, if any.
+	measured *zeus.Temperature
 }
 
-func NewClimateControllable(forceHumidity bool) *ClimateControllable {
+func NewClimateControllable(forceHumidity bool, limits zeus.HardwareLimits) *ClimateControllable {
 	return &ClimateControllable{
+		limits:            limits,
 		celaenoResetGuard: time.Now(),
 		zeusResetGuard:    time.Now(),
 		withCelaeno:       forceHumidity,
@@ -56,24 +68,50 @@ func (c *ClimateControllable) SetDevices(devices map[arke.NodeClass]*Device) {
 	}
 }
 
+func (c *ClimateControllable) Channels() []*zeus.Channel {
+	if c.withCelaeno == true {
+		return []*zeus.Channel{zeus.TemperatureChannel, zeus.HumidityChannel, zeus.WindChannel}
+	}
+	return []*zeus.Channel{zeus.TemperatureChannel, zeus.WindChannel}
+}
+
 func (c *ClimateControllable) Close() error {
 	return nil
 }
 
+func (c *ClimateControllable) setPoint() *arke.ZeusSetPoint {
+	c.mx.Lock()
+	defer c.mx.Unlock()
+	return c.lastSetPoint
+}
+
 var zeusFanNames = []string{"Zeus Wind", "Zeus Extraction Right", "Zeus Extraction Left"}
 
+// Action sends the set point of s. An undefined temperature is not
+// commanded: the set point follows the measured temperature, and
+// nothing is sent until a first one is reported.
 func (c *ClimateControllable) Action(s zeus.State) error {
+	c.mx.Lock()
+	defer c.mx.Unlock()
+	if zeus.IsUndefined(s.Temperature) == true {
+		if c.measured == nil {
+			return nil
+		}
+		s.Temperature = *c.measured
+	}
 	if c.withCelaeno == true {
+		// a vpd or absolute humidity target follows the current
+		// temperature
 		c.lastSetPoint = &arke.ZeusSetPoint{
-			Temperature: float32(zeus.Clamp(s.Temperature)),
-			Humidity:    float32(zeus.Clamp(s.Humidity)),
-			Wind:        uint8(zeus.Clamp(s.Wind) / 100.0 * 255),
+			Temperature: float32(c.limits.Temperature.Clamp(s.Temperature.Value())),
+			Humidity:    float32(c.limits.Humidity.Clamp(s.EffectiveHumidity().Value())),
+			Wind:        uint8(c.limits.Wind.Clamp(s.Wind.Value()) / 100.0 * 255),
 		}
 	} else {
 		c.lastSetPoint = &arke.ZeusSetPoint{
-			Temperature: float32(zeus.Clamp(s.Temperature)),
-			Humidity:    float32(s.Humidity.MinValue()),
-			Wind:        uint8(zeus.Clamp(s.Wind) / 100.0 * 255),
+			Temperature: float32(c.limits.Temperature.Clamp(s.Temperature.Value())),
+			Humidity:    float32(c.limits.Humidity.Min),
+			Wind:        uint8(c.limits.Wind.Clamp(s.Wind.Value()) / 100.0 * 255),
 		}
 	}
 	return c.zeus.SendMessage(c.lastSetPoint)
@@ -112,6 +150,17 @@ func (c *ClimateControllable) Callbacks() map[arke.MessageClass]callback {
 			return nil
 		}
 	}
+	res[arke.ZeusReportMessage] = func(alarms chan<- zeus.Alarm, mm *StampedMessage) error {
+		m, ok := mm.M.(*arke.ZeusReport)
+		if ok == false {
+			return fmt.Errorf("Invalid message type %v", mm.M.MessageClassID())
+		}
+		measured := zeus.Temperature(m.Temperature[0])
+		c.mx.Lock()
+		defer c.mx.Unlock()
+		c.measured = &measured
+		return nil
+	}
 	res[arke.ZeusStatusMessage] = func(alarms chan<- zeus.Alarm, mm *StampedMessage) error {
 		m, ok := mm.M.(*arke.ZeusStatus)
 		if ok == false {
@@ -125,8 +174,8 @@ func (c *ClimateControllable) Callbacks() map[arke.MessageClass]callback {
 					c.zeusResetGuard = time.Now().Add(FanResetWindow)
 					c.zeus.SendResetRequest()
 				}
-			} else if c.lastSetPoint != nil {
-				if err := c.zeus.SendMessage(c.lastSetPoint); err != nil {
+			} else if lastSetPoint := c.setPoint(); lastSetPoint != nil {
+				if err := c.zeus.SendMessage(lastSetPoint); err != nil {
 					return err
 				}
 			} else {
@@ -164,10 +213,11 @@ func (c *ClimateControllable) Callbacks() map[arke.MessageClass]callback {
 
 type LightControllable struct {
 	helios *Device
+	limits zeus.HardwareLimits
 }
 
-func NewLightControllable() *LightControllable {
-	return &LightControllable{}
+func NewLightControllable(limits zeus.HardwareLimits) *LightControllable {
+	return &LightControllable{limits: limits}
 }
 
 func (c *LightControllable) Requirements() []arke.NodeClass {
@@ -183,11 +233,15 @@ func (c *LightControllable) SetDevices(devices map[arke.NodeClass]*Device) {
 
 func (c *LightControllable) Action(s zeus.State) error {
 	return c.helios.SendMessage(&arke.HeliosSetPoint{
-		Visible: uint8(zeus.Clamp(s.VisibleLight) * 255 / 100),
-		UV:      uint8(zeus.Clamp(s.UVLight) * 255 / 100),
+		Visible: uint8(c.limits.VisibleLight.Clamp(s.VisibleLight.Value()) * 255 / 100),
+		UV:      uint8(c.limits.UVLight.Clamp(s.UVLight.Value()) * 255 / 100),
 	})
 }
 
+func (c *LightControllable) Channels() []*zeus.Channel {
+	return []*zeus.Channel{zeus.VisibleLightChannel, zeus.UVLightChannel}
+}
+
 func (c *LightControllable) Close() error {
 	return nil
 }
@@ -201,8 +255,24 @@ type ClimateRecordable struct {
 	MaxTemperature zeus.Temperature
 	MinHumidity    zeus.Humidity
 	MaxHumidity    zeus.Humidity
-	NumAux         int
-	Notifiers      []chan<- zeus.ClimateReport
+	AlarmDelay     time.Duration
+	Hysteresis     zeus.AlarmHysteresis
+	// MaxTemperatureRate and MaxHumidityRate are the fastest changes
+	// per minute allowed outside of transitions. Zero disables them.
+	MaxTemperatureRate float64
+	MaxHumidityRate    float64
+	NumAux             int
+	Notifiers          []chan<- zeus.ClimateReport
+
+	mx          sync.RWMutex
+	temperature boundViolation
+	humidity    boundViolation
+	rates       climateRates
+	schedule    zeus.ClimateInterpoler
+	// overrides are the times the schedule was overridden or
+	// resumed, overrideUntil the end of the current override.
+	overrides     []time.Time
+	overrideUntil time.Time
 }
 
 func NewClimateRecordableCapability(minT, maxT zeus.Temperature, minH, maxH zeus.Humidity, numAux int, notifiers []chan<- zeus.ClimateReport) capability {
@@ -213,11 +283,134 @@ func NewClimateRecordableCapability(minT, maxT zeus.Temperature, minH, maxH zeus
 		MaxHumidity:    maxH,
 		NumAux:         numAux,
 		Notifiers:      notifiers,
+		rates:          climateRates{window: zeus.DefaultRateWindow},
+	}
+
+	return res
+}
+
+// SetBounds changes the alarm bounds while the zone is running.
+func (r *ClimateRecordable) SetBounds(minT, maxT zeus.Temperature, minH, maxH zeus.Humidity) {
+	r.mx.Lock()
+	defer r.mx.Unlock()
+	r.MinTemperature = minT
+	r.MaxTemperature = maxT
+	r.MinHumidity = minH
+	r.MaxHumidity = maxH
+}
+
+// SetAlarmFilter changes how long a value should be out of bound
+// before an alarm is raised, and the margins it should come back
+// within before the alarm clears.
+func (r *ClimateRecordable) SetAlarmFilter(delay time.Duration, hysteresis zeus.AlarmHysteresis) {
+	r.mx.Lock()
+	defer r.mx.Unlock()
+	r.AlarmDelay = delay
+	r.Hysteresis = hysteresis
+}
+
+// SetRateLimits changes the fastest rates of change allowed, measured
+// over window. A zero window uses zeus.DefaultRateWindow.
+func (r *ClimateRecordable) SetRateLimits(window time.Duration, temperature, humidity float64) {
+	r.mx.Lock()
+	defer r.mx.Unlock()
+	if window == 0 {
+		window = zeus.DefaultRateWindow
 	}
+	r.rates.window = window
+	r.MaxTemperatureRate = temperature
+	r.MaxHumidityRate = humidity
+}
+
+// SetSchedule sets the climate schedule, whose transitions are
+// allowed to change the climate faster than the rate limits. It
+// should not be used concurrently elsewhere.
+func (r *ClimateRecordable) SetSchedule(schedule zeus.ClimateInterpoler) {
+	r.mx.Lock()
+	defer r.mx.Unlock()
+	r.schedule = schedule
+}
 
+// SetOverride tells that the schedule is overridden from now until
+// until, or resumed at now if until is zero. Like transitions, both
+// changes are allowed to change the climate faster than the rate
+// limits.
+func (r *ClimateRecordable) SetOverride(now, until time.Time) {
+	r.mx.Lock()
+	defer r.mx.Unlock()
+	r.overrides = append(r.overrides, now)
+	r.overrideUntil = until
+}
+
+// inTransition returns true if the schedule transitions between two
+// states, or is overridden or resumed, at any time between start and
+// end.
+func (r *ClimateRecordable) inTransition(start, end time.Time) bool {
+	if r.overrideUntil.Before(start) == false && r.overrideUntil.After(end) == false {
+		return true
+	}
+	for _, t := range r.overrides {
+		if t.Before(start) == false && t.After(end) == false {
+			return true
+		}
+	}
+	if r.schedule == nil {
+		return false
+	}
+	for _, s := range zeus.Timeline(r.schedule, start, end) {
+		if s.Transition == true {
+			return true
+		}
+	}
+	return false
+}
+
+// dropOverrides forgets the overrides older than t.
+func (r *ClimateRecordable) dropOverrides(t time.Time) {
+	i := 0
+	for ; i < len(r.overrides); i++ {
+		if r.overrides[i].Before(t) == false {
+			break
+		}
+	}
+	r.overrides = r.overrides[i:]
+}
+
+// checkRates adds report to the measured rates, and returns the
+// alarms for the rates above their limits.
+func (r *ClimateRecordable) checkRates(report zeus.ClimateReport) []zeus.Alarm {
+	r.mx.Lock()
+	defer r.mx.Unlock()
+	if r.MaxTemperatureRate == 0 && r.MaxHumidityRate == 0 {
+		return nil
+	}
+	r.rates.add(report)
+	temperature, humidity, ok := r.rates.rates()
+	if ok == false {
+		return nil
+	}
+	res := []zeus.Alarm{}
+	if r.MaxTemperatureRate > 0 && math.Abs(temperature) > r.MaxTemperatureRate {
+		res = append(res, zeus.NewTemperatureRateAlarm(temperature))
+	}
+	if r.MaxHumidityRate > 0 && math.Abs(humidity) > r.MaxHumidityRate {
+		res = append(res, zeus.NewHumidityRateAlarm(humidity))
+	}
+	// the climate keeps changing quickly for a while after an
+	// instantaneous transition or an override, a grace period of
+	// one window is given after them.
+	grace := r.rates.start().Add(-r.rates.window)
+	r.dropOverrides(grace)
+	if len(res) == 0 || r.inTransition(grace, report.Time) == true {
+		return nil
+	}
 	return res
 }
 
+func (r *ClimateRecordable) Channels() []*zeus.Channel {
+	return nil
+}
+
 func (r *ClimateRecordable) Close() error {
 	for _, n := range r.Notifiers {
 		close(n)
@@ -234,17 +427,50 @@ func (r *ClimateRecordable) SetDevices(map[arke.NodeClass]*Device) {}
 func (r *ClimateRecordable) Action(s zeus.State) error { return nil }
 
 func checkBound(v, min, max zeus.BoundedUnit) bool {
-	if zeus.IsUndefined(min) == false && v.Value() < min.Value() {
+	return checkBoundWithMargin(v, min, max, 0.0)
+}
+
+// checkBoundWithMargin checks v against bounds narrowed by margin.
+func checkBoundWithMargin(v, min, max zeus.BoundedUnit, margin float64) bool {
+	if zeus.IsUndefined(min) == false && v.Value() < min.Value()+margin {
 		return false
 	}
 
-	if zeus.IsUndefined(max) == false && v.Value() > max.Value() {
+	if zeus.IsUndefined(max) == false && v.Value() > max.Value()-margin {
 		return false
 	}
 
 	return true
 }
 
+// boundViolation tracks a value out of its alarm bounds.
+type boundViolation struct {
+	since time.Time
+	fired bool
+}
+
+// update returns true if v, reported at t, violates its bounds. A
+// violation fires once it lasted delay, and then only ends when v
+// comes back within the bounds narrowed by hysteresis. Before that,
+// any value within bounds ends it.
+func (b *boundViolation) update(t time.Time, v, min, max zeus.BoundedUnit, delay time.Duration, hysteresis float64) bool {
+	if checkBound(v, min, max) == false {
+		if b.since.IsZero() == true {
+			b.since = t
+		}
+		if t.Sub(b.since) >= delay {
+			b.fired = true
+		}
+		return true
+	}
+	if b.fired == true && checkBoundWithMargin(v, min, max, hysteresis) == false {
+		return true
+	}
+	b.since = time.Time{}
+	b.fired = false
+	return false
+}
+
 func (r *ClimateRecordable) Callbacks() map[arke.MessageClass]callback {
 	return map[arke.MessageClass]callback{
 		arke.ZeusReportMessage: func(alarms chan<- zeus.Alarm, mm *StampedMessage) error {
@@ -253,12 +479,21 @@ func (r *ClimateRecordable) Callbacks() map[arke.MessageClass]callback {
 				return fmt.Errorf("Invalid Message Type %v", mm.M.MessageClassID())
 			}
 
-			if checkBound(zeus.Humidity(report.Humidity), r.MinHumidity, r.MaxHumidity) == false {
-				alarms <- zeus.HumidityOutOfBound
+			// callbacks may run concurrently, and update the
+			// violations.
+			r.mx.Lock()
+			delay := r.AlarmDelay
+			humidityViolated := r.humidity.update(mm.T, zeus.Humidity(report.Humidity), r.MinHumidity, r.MaxHumidity, delay, r.Hysteresis.Humidity)
+			temperatureViolated := r.temperature.update(mm.T, zeus.Temperature(report.Temperature[0]), r.MinTemperature, r.MaxTemperature, delay, r.Hysteresis.Temperature)
+			humiditySince, temperatureSince := r.humidity.since, r.temperature.since
+			r.mx.Unlock()
+
+			if humidityViolated == true {
+				alarms <- zeus.NewOutOfBoundAlarm(zeus.HumidityOutOfBound, humiditySince, delay)
 			}
 
-			if checkBound(zeus.Temperature(report.Temperature[0]), r.MinTemperature, r.MaxTemperature) == false {
-				alarms <- zeus.TemperatureOutOfBound
+			if temperatureViolated == true {
+				alarms <- zeus.NewOutOfBoundAlarm(zeus.TemperatureOutOfBound, temperatureSince, delay)
 			}
 
 			temperatures := make([]zeus.Temperature, 0, r.NumAux+1)
@@ -273,6 +508,9 @@ func (r *ClimateRecordable) Callbacks() map[arke.MessageClass]callback {
 			}
 
 			if creport.Check() == nil {
+				for _, a := range r.checkRates(creport) {
+					alarms <- a
+				}
 				for _, n := range r.Notifiers {
 					n <- creport
 				}
@@ -285,57 +523,98 @@ func (r *ClimateRecordable) Callbacks() map[arke.MessageClass]callback {
 }
 
 func ComputeClimateRequirements(climate zeus.ZoneClimate, definition ZoneDefinition, reporters []ClimateReporter) []capability {
+	chans := []chan<- zeus.ClimateReport{}
+	for _, n := range reporters {
+		chans = append(chans, n.ReportChannel())
+	}
+	return computeCapabilities(climate, definition, len(reporters) > 0, chans)
+}
+
+// computeCapabilities returns the capabilities needed to run
+// climate. The climate is recorded if record is true, or if climate
+// defines alarm bounds or rates.
+func computeCapabilities(climate zeus.ZoneClimate, definition ZoneDefinition, record bool, notifiers []chan<- zeus.ClimateReport) []capability {
 	res := []capability{}
 
-	needClimateReport := len(reporters) > 0
+	needClimateReport := record
 	if zeus.IsUndefined(climate.MinimalTemperature) == false || zeus.IsUndefined(climate.MaximalTemperature) == false {
 		needClimateReport = true
 	}
 	if zeus.IsUndefined(climate.MinimalHumidity) == false || zeus.IsUndefined(climate.MaximalHumidity) == false {
 		needClimateReport = true
 	}
+	if climate.MaximalTemperatureRate > 0 || climate.MaximalHumidityRate > 0 {
+		needClimateReport = true
+	}
 
 	if needClimateReport == true {
-		chans := []chan<- zeus.ClimateReport{}
-		for _, n := range reporters {
-			chans = append(chans, n.ReportChannel())
-		}
-
-		res = append(res, NewClimateRecordableCapability(climate.MinimalTemperature,
+		recordable := NewClimateRecordableCapability(climate.MinimalTemperature,
 			climate.MaximalTemperature,
 			climate.MinimalHumidity,
 			climate.MaximalHumidity,
 			definition.TemperatureAux,
-			chans))
+			notifiers).(*ClimateRecordable)
+		recordable.SetAlarmFilter(climate.AlarmDelay, climate.Hysteresis)
+		recordable.SetRateLimits(climate.RateWindow, climate.MaximalTemperatureRate, climate.MaximalHumidityRate)
+		res = append(res, recordable)
+	}
+
+	used := usedChannels(climate)
+	for _, new := range channelCapabilities {
+		c := new(used, definition)
+		for _, channel := range c.Channels() {
+			if used[channel] == true {
+				res = append(res, c)
+				break
+			}
+		}
 	}
 
-	controlLight := false
-	controlTemperature := false
-	controlHumidity := false
-	controlWind := false
+	return res
+}
 
+// usedChannels returns the channels defined by any state of climate.
+func usedChannels(climate zeus.ZoneClimate) map[*zeus.Channel]bool {
+	res := map[*zeus.Channel]bool{}
 	for _, s := range climate.States {
-		if zeus.IsUndefined(s.Humidity) == false {
-			controlHumidity = true
-		}
-		if zeus.IsUndefined(s.Temperature) == false {
-			controlTemperature = true
-		}
-		if zeus.IsUndefined(s.Wind) == false {
-			controlWind = true
+		for _, c := range zeus.Channels() {
+			if c.Defined(s) == true {
+				res[c] = true
+			}
 		}
-		if zeus.IsUndefined(s.VisibleLight) == false || zeus.IsUndefined(s.UVLight) == false {
-			controlLight = true
+		if s.HumidityTarget != nil {
+			res[zeus.HumidityChannel] = true
 		}
 	}
+	return res
+}
 
-	if controlTemperature == true || controlWind == true {
-		res = append(res, NewClimateControllable(controlHumidity))
-	}
+// channelCapabilities create the capabilities which may drive the
+// channels used by a zone. A capability is needed if any channel it
+// drives is used.
+var channelCapabilities = []func(used map[*zeus.Channel]bool, definition ZoneDefinition) capability{
+	func(used map[*zeus.Channel]bool, definition ZoneDefinition) capability {
+		return NewClimateControllable(used[zeus.HumidityChannel], definition.HardwareLimits())
+	},
+	func(used map[*zeus.Channel]bool, definition ZoneDefinition) capability {
+		return NewLightControllable(definition.HardwareLimits())
+	},
+}
 
-	if controlLight == true {
-		res = append(res, NewLightControllable())
+// checkDrivenChannels returns an error if climate uses a channel
+// that no capability drives.
+func checkDrivenChannels(climate zeus.ZoneClimate, definition ZoneDefinition) error {
+	driven := map[*zeus.Channel]bool{}
+	for _, c := range computeCapabilities(climate, definition, false, nil) {
+		for _, channel := range c.Channels() {
+			driven[channel] = true
+		}
 	}
-
-	return res
+	used := usedChannels(climate)
+	for _, c := range zeus.Channels() {
+		if used[c] == true && driven[c] == false {
+			return fmt.Errorf("no device drives channel '%s'", c.Name)
+		}
+	}
+	return nil
 }
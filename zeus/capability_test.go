@@ -0,0 +1,230 @@
+package main
+
+import (
+	"math"
+	"reflect"
+	"time"
+
+	"github.com/formicidae-tracker/libarke/src-go/arke"
+	"github.com/formicidae-tracker/zeus"
+	. "gopkg.in/check.v1"
+)
+
+type CapabilitySuite struct{}
+
+var _ = Suite(&CapabilitySuite{})
+
+func (s *CapabilitySuite) TestRecordableHysteresis(c *C) {
+	r := NewClimateRecordableCapability(20, 30, zeus.UndefinedHumidity, zeus.UndefinedHumidity, 0, nil).(*ClimateRecordable)
+	r.SetAlarmFilter(5*time.Minute, zeus.AlarmHysteresis{Temperature: 1})
+	callback := r.Callbacks()[arke.ZeusReportMessage]
+
+	start := time.Now()
+	testdata := []struct {
+		Temperature float32
+		Since       time.Duration
+		Raised      bool
+	}{
+		{25, 0, false},
+		{30.5, 1 * time.Minute, true},
+		// back in bound before the alarm delay
+		{29.5, 0, false},
+		{31, 3 * time.Minute, true},
+		{31, 3 * time.Minute, true},
+		{31, 3 * time.Minute, true},
+		{31, 3 * time.Minute, true},
+		{31, 3 * time.Minute, true},
+		// the alarm fires after the delay
+		{31, 3 * time.Minute, true},
+		// within bound, but not below maximum minus hysteresis
+		{29.5, 3 * time.Minute, true},
+		{28.5, 0, false},
+		{19, 11 * time.Minute, true},
+	}
+	for i, d := range testdata {
+		alarms := make(chan zeus.Alarm, 2)
+		t := start.Add(time.Duration(i) * time.Minute)
+		c.Assert(callback(alarms, &StampedMessage{
+			M: &arke.ZeusReport{Humidity: 50, Temperature: [4]float32{d.Temperature, 0, 0, 0}},
+			T: t,
+		}), IsNil)
+		close(alarms)
+		a, ok := <-alarms
+		c.Check(ok, Equals, d.Raised, Commentf("report %d at %g°C", i, d.Temperature))
+		if ok == false {
+			continue
+		}
+		c.Check(a.Reason(), Equals, zeus.TemperatureOutOfBound.Reason())
+		delayed, ok := a.(zeus.DelayedAlarm)
+		c.Assert(ok, Equals, true)
+		c.Check(delayed.Delay(), Equals, 5*time.Minute)
+		if d.Since != 0 {
+			c.Check(delayed.Since().Equal(start.Add(d.Since)), Equals, true)
+		}
+	}
+}
+
+func (s *CapabilitySuite) TestRecordableRates(c *C) {
+	climate := zeus.ZoneClimate{
+		MinimalTemperature:     zeus.UndefinedTemperature,
+		MaximalTemperature:     zeus.UndefinedTemperature,
+		MinimalHumidity:        zeus.UndefinedHumidity,
+		MaximalHumidity:        zeus.UndefinedHumidity,
+		MaximalTemperatureRate: 0.5,
+		MaximalHumidityRate:    2,
+		RateWindow:             4 * time.Minute,
+		States: []zeus.State{
+			{Name: "night", Temperature: 20, Humidity: zeus.UndefinedHumidity, Wind: zeus.UndefinedWind, VisibleLight: zeus.UndefinedLight, UVLight: zeus.UndefinedLight},
+			{Name: "day", Temperature: 26, Humidity: zeus.UndefinedHumidity, Wind: zeus.UndefinedWind, VisibleLight: zeus.UndefinedLight, UVLight: zeus.UndefinedLight},
+		},
+		Transitions: []zeus.Transition{
+			{From: "night", To: "day", Start: time.Date(0, 1, 1, 6, 0, 0, 0, time.UTC), Duration: time.Hour},
+			{From: "day", To: "night", Start: time.Date(0, 1, 1, 18, 0, 0, 0, time.UTC)},
+		},
+	}
+	reference := time.Date(2021, 3, 1, 0, 0, 0, 0, time.UTC)
+	schedule, err := zeus.NewZoneClimateInterpoler(climate, reference)
+	c.Assert(err, IsNil)
+
+	capabilities := computeCapabilities(climate, ZoneDefinition{}, false, nil)
+	c.Assert(len(capabilities) > 0, Equals, true)
+	r, ok := capabilities[0].(*ClimateRecordable)
+	c.Assert(ok, Equals, true)
+	r.SetSchedule(schedule)
+	callback := r.Callbacks()[arke.ZeusReportMessage]
+
+	// sends a report every 30s, where the temperature rises by
+	// 1°C/min, and returns the raised alarms.
+	heat := func(start time.Time) []zeus.Alarm {
+		res := []zeus.Alarm{}
+		for i := 0; i < 8; i++ {
+			alarms := make(chan zeus.Alarm, 2)
+			c.Assert(callback(alarms, &StampedMessage{
+				M: &arke.ZeusReport{Humidity: 50, Temperature: [4]float32{20 + 0.5*float32(i), 0, 0, 0}},
+				T: start.Add(time.Duration(i) * 30 * time.Second),
+			}), IsNil)
+			close(alarms)
+			for a := range alarms {
+				res = append(res, a)
+			}
+		}
+		return res
+	}
+
+	// tolerated during the scheduled transition
+	c.Check(heat(reference.Add(6*time.Hour+10*time.Minute)), HasLen, 0)
+	// or when the window overlaps it
+	c.Check(heat(reference.Add(7*time.Hour-time.Minute)), HasLen, 0)
+
+	alarms := heat(reference.Add(12 * time.Hour))
+	// raised once the reports span half of the window
+	c.Assert(alarms, HasLen, 4)
+	for _, a := range alarms {
+		rate, ok := a.(zeus.RateAlarm)
+		c.Assert(ok, Equals, true)
+		c.Check(rate.Reason(), Equals, "Temperature is changing too fast")
+		c.Check(math.Abs(rate.Rate()-1.0) < 1e-6, Equals, true, Commentf("rate: %g", rate.Rate()))
+	}
+
+	// tolerated for a window after an override starts or is
+	// cleared, but not in between
+	r.SetOverride(reference.Add(13*time.Hour), reference.Add(16*time.Hour))
+	c.Check(heat(reference.Add(13*time.Hour+2*time.Minute)), HasLen, 0)
+	c.Check(heat(reference.Add(13*time.Hour+20*time.Minute)), HasLen, 4)
+	r.SetOverride(reference.Add(15*time.Hour), time.Time{})
+	c.Check(heat(reference.Add(15*time.Hour+2*time.Minute)), HasLen, 0)
+	// the cleared override does not end anymore
+	c.Check(heat(reference.Add(16*time.Hour+2*time.Minute)), HasLen, 4)
+
+	// or after an instantaneous transition
+	c.Check(heat(reference.Add(18*time.Hour+2*time.Minute)), HasLen, 0)
+	c.Check(heat(reference.Add(18*time.Hour+10*time.Minute)), HasLen, 4)
+}
+
+func (s *CapabilitySuite) TestClimateRates(c *C) {
+	rates := climateRates{window: 2 * time.Minute}
+	start := time.Date(2021, 3, 1, 12, 0, 0, 0, time.UTC)
+	// noisy humidity dropping by 2% R.H./min
+	humidities := []zeus.Humidity{60, 59.5, 58, 57.5, 56, 55.5}
+	for i, h := range humidities {
+		rates.add(zeus.ClimateReport{
+			Time:         start.Add(time.Duration(i) * 30 * time.Second),
+			Humidity:     h,
+			Temperatures: []zeus.Temperature{22},
+		})
+	}
+	// the first report is out of the window
+	c.Check(rates.start(), Equals, start.Add(30*time.Second))
+	temperature, humidity, ok := rates.rates()
+	c.Check(ok, Equals, true)
+	c.Check(temperature, Equals, 0.0)
+	c.Check(math.Abs(humidity+2.0) < 1e-6, Equals, true, Commentf("rate: %g", humidity))
+}
+
+func (s *CapabilitySuite) TestChannelCapabilities(c *C) {
+	state := zeus.State{
+		Name:         "day",
+		Temperature:  zeus.UndefinedTemperature,
+		Humidity:     60,
+		Wind:         zeus.UndefinedWind,
+		VisibleLight: zeus.UndefinedLight,
+		UVLight:      zeus.UndefinedLight,
+	}
+	names := func(climate zeus.ZoneClimate) []string {
+		res := []string{}
+		for _, c := range computeCapabilities(climate, ZoneDefinition{}, false, nil) {
+			res = append(res, reflect.TypeOf(c).Elem().Name())
+		}
+		return res
+	}
+	// humidity is driven with a Celaeno
+	climate := zeus.ZoneClimate{States: []zeus.State{state}}
+	c.Check(names(climate), DeepEquals, []string{"ClimateRecordable", "ClimateControllable"})
+	c.Check(checkDrivenChannels(climate, ZoneDefinition{}), IsNil)
+
+	state.Humidity = zeus.UndefinedHumidity
+	state.UVLight = 20
+	climate = zeus.ZoneClimate{States: []zeus.State{state}}
+	c.Check(names(climate), DeepEquals, []string{"ClimateRecordable", "LightControllable"})
+	c.Check(checkDrivenChannels(climate, ZoneDefinition{}), IsNil)
+}
+
+func (s *CapabilitySuite) TestHumidityOnlyControl(c *C) {
+	state := zeus.State{
+		Name:         "day",
+		Temperature:  zeus.UndefinedTemperature,
+		Humidity:     60,
+		Wind:         zeus.UndefinedWind,
+		VisibleLight: zeus.UndefinedLight,
+		UVLight:      zeus.UndefinedLight,
+	}
+	climate := zeus.ZoneClimate{States: []zeus.State{state}}
+	capabilities := computeCapabilities(climate, ZoneDefinition{}, false, nil)
+	c.Assert(capabilities, HasLen, 2)
+	controllable, ok := capabilities[1].(*ClimateControllable)
+	c.Assert(ok, Equals, true)
+	intf := NewStubRawInterface()
+	defer intf.Close()
+	controllable.SetDevices(map[arke.NodeClass]*Device{
+		arke.ZeusClass:    &Device{Class: arke.ZeusClass, ID: 1, intf: intf},
+		arke.CelaenoClass: &Device{Class: arke.CelaenoClass, ID: 1, intf: intf},
+	})
+
+	// the temperature is not commanded until it is measured
+	c.Assert(controllable.Action(state), IsNil)
+	c.Check(controllable.setPoint(), IsNil)
+
+	alarms := make(chan zeus.Alarm, 1)
+	c.Assert(controllable.Callbacks()[arke.ZeusReportMessage](alarms, &StampedMessage{
+		M: &arke.ZeusReport{Humidity: 50, Temperature: [4]float32{24.5, 0, 0, 0}},
+	}), IsNil)
+	c.Assert(controllable.Action(state), IsNil)
+	c.Check(*controllable.setPoint(), Equals, arke.ZeusSetPoint{Temperature: 24.5, Humidity: 60, Wind: 0})
+
+	// a vpd target follows the measured temperature
+	state.Humidity = zeus.UndefinedHumidity
+	state.HumidityTarget = &zeus.HumidityTarget{VaporPressureDeficit: 1.0}
+	c.Assert(controllable.Action(state), IsNil)
+	expected := zeus.HumidityTarget{VaporPressureDeficit: 1.0}.RelativeHumidity(24.5)
+	c.Check(controllable.setPoint().Humidity, Equals, float32(expected))
+}
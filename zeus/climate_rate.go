@@ -0,0 +1,71 @@
+package main
+
+import (
+	"time"
+
+	"github.com/formicidae-tracker/zeus"
+)
+
+// climateRates measures the rates of change of the temperature and
+// humidity over a sliding window of ClimateReport.
+type climateRates struct {
+	window  time.Duration
+	reports []zeus.ClimateReport
+}
+
+// add adds r to the window, and drops the reports older than the
+// window.
+func (c *climateRates) add(r zeus.ClimateReport) {
+	c.reports = append(c.reports, r)
+	start := r.Time.Add(-c.window)
+	i := 0
+	for ; i < len(c.reports); i++ {
+		if c.reports[i].Time.Before(start) == false {
+			break
+		}
+	}
+	c.reports = c.reports[i:]
+}
+
+// start returns the time of the oldest report in the window.
+func (c *climateRates) start() time.Time {
+	if len(c.reports) == 0 {
+		return time.Time{}
+	}
+	return c.reports[0].Time
+}
+
+// rates returns the temperature and humidity rates of change, per
+// minute. They are least squares slopes, which are less sensitive to
+// the sensor noise than the difference between the oldest and newest
+// reports. It returns false until the reports span half of the window.
+func (c *climateRates) rates() (temperature, humidity float64, ok bool) {
+	if len(c.reports) < 2 {
+		return 0, 0, false
+	}
+	first := c.reports[0].Time
+	if c.reports[len(c.reports)-1].Time.Sub(first) < c.window/2 {
+		return 0, 0, false
+	}
+	n := float64(len(c.reports))
+	var sumT, sumTT, sumTemperature, sumHumidity float64
+	for _, r := range c.reports {
+		t := r.Time.Sub(first).Minutes()
+		sumT += t
+		sumTT += t * t
+		sumTemperature += r.Temperatures[0].Value()
+		sumHumidity += r.Humidity.Value()
+	}
+	meanT := sumT / n
+	variance := sumTT/n - meanT*meanT
+	if variance <= 0 {
+		return 0, 0, false
+	}
+	var covTemperature, covHumidity float64
+	for _, r := range c.reports {
+		dt := r.Time.Sub(first).Minutes() - meanT
+		covTemperature += dt * (r.Temperatures[0].Value() - sumTemperature/n)
+		covHumidity += dt * (r.Humidity.Value() - sumHumidity/n)
+	}
+	return covTemperature / n / variance, covHumidity / n / variance, true
+}
@@ -31,7 +31,7 @@ func (n *fileClimateReporter) ReportChannel() chan<- zeus.ClimateReport {
 
 func (n *fileClimateReporter) Report(ready chan<- struct{}) {
 	close(ready)
-	asInterface := make([]interface{}, n.NumAux+3)
+	asInterface := make([]interface{}, n.NumAux+5)
 	for cr := range n.Chan {
 		if len(cr.Temperatures) != n.NumAux+1 {
 			continue
@@ -41,6 +41,8 @@ func (n *fileClimateReporter) Report(ready chan<- struct{}) {
 		for i, t := range cr.Temperatures {
 			asInterface[i+2] = t
 		}
+		asInterface[n.NumAux+3] = cr.VaporPressureDeficit()
+		asInterface[n.NumAux+4] = cr.AbsoluteHumidity()
 
 		fmt.Fprintf(n.File,
 			n.Format,
@@ -63,15 +65,62 @@ func NewFileClimateReporter(filename string, numAux int) (ClimateReporter, strin
 		return nil, "", err
 	}
 
-	res.Format = "%d %.2f %.2f" + strings.Repeat(" %.2f", numAux) + "\n"
+	res.Format = climateFileFormat(numAux)
+	fmt.Fprintf(res.File, "# Starting date %s\n%s\n", res.Start.Format(time.RFC3339Nano), climateFileHeader(numAux))
+
+	return res, fname, nil
+}
+
+func climateFileFormat(numAux int) string {
+	return "%d %.2f %.2f" + strings.Repeat(" %.2f", numAux) + " %.3f %.2f\n"
+}
+
+// climateFileHeader returns the header of the climate log. Derived
+// values are written after the temperatures, so the log can be read
+// by tools which only expect humidity and temperatures.
+func climateFileHeader(numAux int) string {
 	header := "# Time (ms) Relative Humidity (%) Temperature (°C)"
 	for i := 0; i < numAux; i++ {
 		header += fmt.Sprintf(" Aux %d (°C)", i+1)
 	}
+	return header + " VPD (kPa) Absolute Humidity (g/m³)"
+}
 
-	fmt.Fprintf(res.File, "# Starting date %s\n%s\n", res.Start.Format(time.RFC3339Nano), header)
+func readClimateFileHeader(filename string) (time.Time, string, error) {
+	f, err := os.Open(filename)
+	if err != nil {
+		return time.Time{}, "", err
+	}
+	defer f.Close()
+	reader := bufio.NewReader(f)
+	start, err := readStartDate(reader)
+	if err != nil {
+		return time.Time{}, "", err
+	}
+	header, err := reader.ReadString('\n')
+	return start, strings.TrimSpace(header), err
+}
 
-	return res, fname, nil
+// ResumeFileClimateReporter appends to an existing climate log,
+// keeping its starting date. If the file does not exist or cannot be
+// resumed, it fallbacks to NewFileClimateReporter.
+func ResumeFileClimateReporter(filename string, numAux int) (ClimateReporter, string, error) {
+	start, header, err := readClimateFileHeader(filename)
+	if err != nil || header != climateFileHeader(numAux) {
+		return NewFileClimateReporter(filename, numAux)
+	}
+
+	res := &fileClimateReporter{
+		Chan:   make(chan zeus.ClimateReport, 10),
+		Start:  start,
+		NumAux: numAux,
+		Format: climateFileFormat(numAux),
+	}
+	res.File, err = os.OpenFile(filename, os.O_APPEND|os.O_WRONLY, 0644)
+	if err != nil {
+		return nil, "", err
+	}
+	return res, filename, nil
 }
 
 func readStartDate(r *bufio.Reader) (time.Time, error) {
@@ -6,6 +6,7 @@ import (
 	"os"
 	"regexp"
 
+	"github.com/formicidae-tracker/zeus"
 	flags "github.com/jessevdk/go-flags"
 	yaml "gopkg.in/yaml.v2"
 )
@@ -14,6 +15,17 @@ type ZoneDefinition struct {
 	CANInterface   string `yaml:"can-interface"`
 	DevicesID      uint   `yaml:"devices-id"`
 	TemperatureAux int    `yaml:"temperature-aux"`
+	// Limits are the values the zone actuators can reach, if they
+	// differ from the standard hardware.
+	Limits *zeus.HardwareLimits `yaml:"limits,omitempty"`
+}
+
+// HardwareLimits returns the limits of the zone actuators.
+func (d ZoneDefinition) HardwareLimits() zeus.HardwareLimits {
+	if d.Limits == nil {
+		return zeus.DefaultHardwareLimits()
+	}
+	return *d.Limits
 }
 
 func (d ZoneDefinition) ID() string {
@@ -66,6 +78,10 @@ func (c Config) checkZones() error {
 			return fmt.Errorf("Invalid zone definition '%s': invalid devices-id %d ( should be in [1,7])", name, definition.DevicesID)
 		}
 
+		if err := definition.HardwareLimits().Check(); err != nil {
+			return fmt.Errorf("Invalid zone definition '%s': %s", name, err)
+		}
+
 		def := definition.ID()
 
 		if oName, ok := mapping[def]; ok == true {
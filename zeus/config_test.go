@@ -4,7 +4,9 @@ import (
 	"io/ioutil"
 	"os"
 
+	"github.com/formicidae-tracker/zeus"
 	. "gopkg.in/check.v1"
+	yaml "gopkg.in/yaml.v2"
 )
 
 type ConfigSuite struct {
@@ -146,3 +148,27 @@ func (s *ConfigSuite) TestErrorChecking(c *C) {
 		}
 	}
 }
+
+func (s *ConfigSuite) TestHardwareLimits(c *C) {
+	config := &Config{}
+	c.Assert(yaml.Unmarshal([]byte(`zones:
+  cold-room:
+    can-interface: slcan0
+    devices-id: 1
+    limits:
+      temperature: {min: 8}
+`), config), IsNil)
+	limits := config.Zones["cold-room"].HardwareLimits()
+	c.Check(limits.Temperature, Equals, zeus.UnitLimits{Min: 8, Max: 40})
+	c.Check(limits.Humidity, Equals, zeus.DefaultHardwareLimits().Humidity)
+	c.Check(ZoneDefinition{}.HardwareLimits(), Equals, zeus.DefaultHardwareLimits())
+
+	limits.Humidity.Max = 110
+	config = &Config{
+		Interfaces: map[string]string{"slcan0": "/dev/ttyS0"},
+		Zones: map[string]ZoneDefinition{
+			"box": ZoneDefinition{CANInterface: "slcan0", DevicesID: 1, Limits: &limits},
+		},
+	}
+	c.Check(config.Check(), ErrorMatches, `Invalid zone definition 'box': invalid humidity limits \[10,110\]: should be within \[0,100\]`)
+}
@@ -66,11 +66,11 @@ func (s *FileClimateReporterSuite) TestFileNameWriting(c *C) {
 	c.Assert(err, IsNil)
 
 	c.Check(string(data), Equals, fmt.Sprintf(`# Starting date %s
-# Time (ms) Relative Humidity (%%) Temperature (°C) Aux 1 (°C) Aux 2 (°C) Aux 3 (°C)
-0 50.00 21.00 21.00 21.00 21.00
-333 50.00 21.00 21.00 21.00 21.00
-666 50.00 21.00 21.00 21.00 21.00
-999 50.00 21.00 21.00 21.00 21.00
+# Time (ms) Relative Humidity (%%) Temperature (°C) Aux 1 (°C) Aux 2 (°C) Aux 3 (°C) VPD (kPa) Absolute Humidity (g/m³)
+0 50.00 21.00 21.00 21.00 21.00 1.241 9.14
+333 50.00 21.00 21.00 21.00 21.00 1.241 9.14
+666 50.00 21.00 21.00 21.00 21.00 1.241 9.14
+999 50.00 21.00 21.00 21.00 21.00 1.241 9.14
 `, fn.(*fileClimateReporter).Start.Format(time.RFC3339Nano)))
 
 }
@@ -108,6 +108,15 @@ func (s *FileClimateReporterSuite) TestFileReading(c *C) {
 				zeus.ClimateReport{Time: start.Add(502 * time.Millisecond), Humidity: 51.3, Temperatures: []zeus.Temperature{24.5, 15.7}},
 			},
 		},
+		{
+			Content: "# Starting date " + startString + `
+# Time (ms) Relative Humidity (%) Temperature (°C) VPD (kPa) Absolute Humidity (g/m³)
+0 50.0 21.23 1.261 9.27
+`,
+			Expected: []zeus.ClimateReport{
+				zeus.ClimateReport{Time: start, Humidity: 50.0, Temperatures: []zeus.Temperature{21.23}},
+			},
+		},
 		{
 			Content: "# Starting date " + startString + `fo
 # Time (ms) Relative Humidity (%) Temperature (°C) Aux 1 (°C)
@@ -152,3 +161,25 @@ func (s *FileClimateReporterSuite) TestFileReading(c *C) {
 
 	}
 }
+
+func (s *FileClimateReporterSuite) TestFileResuming(c *C) {
+	filename := filepath.Join(s.TmpDir, "resume.txt")
+	fn, fname, err := NewFileClimateReporter(filename, 0)
+	c.Assert(err, IsNil)
+	start := fn.(*fileClimateReporter).Start
+	fn.(*fileClimateReporter).File.Close()
+
+	resumed, rname, err := ResumeFileClimateReporter(filename, 0)
+	c.Assert(err, IsNil)
+	c.Check(rname, Equals, fname)
+	c.Check(resumed.(*fileClimateReporter).Start.Equal(start), Equals, true)
+	resumed.(*fileClimateReporter).File.Close()
+
+	_, rname, err = ResumeFileClimateReporter(filename, 2)
+	c.Assert(err, IsNil)
+	c.Check(rname, Equals, filepath.Join(s.TmpDir, "resume.1.txt"))
+
+	_, rname, err = ResumeFileClimateReporter(filepath.Join(s.TmpDir, "new.txt"), 0)
+	c.Assert(err, IsNil)
+	c.Check(rname, Equals, filepath.Join(s.TmpDir, "new.txt"))
+}
@@ -0,0 +1,47 @@
+package main
+
+import (
+	"sync"
+	"time"
+
+	"github.com/formicidae-tracker/libarke/src-go/arke"
+)
+
+type deviceKey struct {
+	Class arke.NodeClass
+	ID    arke.NodeID
+}
+
+// heartbeatRecord keeps the last heartbeat received from each device
+// of an interface. It outlives the climate runners, so it can be
+// read without touching the bus.
+type heartbeatRecord struct {
+	mx   sync.RWMutex
+	last map[deviceKey]time.Time
+}
+
+func newHeartbeatRecord() *heartbeatRecord {
+	return &heartbeatRecord{last: make(map[deviceKey]time.Time)}
+}
+
+// Record records an heartbeat of a device.
+func (r *heartbeatRecord) Record(class arke.NodeClass, ID arke.NodeID, t time.Time) {
+	r.mx.Lock()
+	defer r.mx.Unlock()
+	r.last[deviceKey{Class: class, ID: ID}] = t
+}
+
+// Last returns the last heartbeat of a device, or nil if it never
+// answered. It can be used on a nil record.
+func (r *heartbeatRecord) Last(class arke.NodeClass, ID arke.NodeID) *time.Time {
+	if r == nil {
+		return nil
+	}
+	r.mx.RLock()
+	defer r.mx.RUnlock()
+	t, ok := r.last[deviceKey{Class: class, ID: ID}]
+	if ok == false {
+		return nil
+	}
+	return &t
+}
@@ -16,6 +16,13 @@ type Interpoler interface {
 	States() <-chan zeus.State
 	Reports() <-chan zeus.StateReport
 
+	// Update replaces the climate being interpolated, without
+	// stopping the interpolation loop.
+	Update(zeus.ClimateInterpoler) error
+	// Override preempts the interpolated states until the override
+	// ends. A nil override resumes the schedule.
+	Override(*zeus.Override) error
+
 	Close() error
 }
 
@@ -25,15 +32,26 @@ type interpoler struct {
 	logger     *log.Logger
 	name       string
 	interpoler zeus.ClimateInterpoler
-	quit       chan struct{}
+	quit, done chan struct{}
+	updates    chan zeus.ClimateInterpoler
+	overrides  chan *zeus.Override
+	override   *zeus.Override
 	states     chan zeus.State
 	reports    chan zeus.StateReport
 }
 
+// state returns the State of cur at now, with the override applied.
+func (i *interpoler) state(cur zeus.Interpolation, now time.Time) zeus.State {
+	if i.override.Active(now) == false {
+		return cur.State(now)
+	}
+	return i.override.Apply(cur.State(now))
+}
+
 func (i *interpoler) stateReport(current, next zeus.Interpolation, now time.Time, nextTime time.Time) zeus.StateReport {
 	report := zeus.StateReport{
 		ZoneIdentifier: i.name,
-		Current:        zeus.SanitizeState(current.State(now)),
+		Current:        zeus.SanitizeState(i.state(current, now)),
 		CurrentEnd:     nil,
 
 		NextTime: nil,
@@ -49,13 +67,32 @@ func (i *interpoler) stateReport(current, next zeus.Interpolation, now time.Time
 		*report.Next = zeus.SanitizeState(next.State(nextTime))
 		report.NextEnd = next.End()
 	}
+	if end, ok := i.interpoler.ExperimentEnd(); ok == true {
+		report.End = &end
+		report.Ended = now.Before(end) == false
+	}
+	if i.override.Active(now) == true {
+		report.Override = &zeus.Override{
+			State: zeus.SanitizeState(i.override.State),
+			Until: i.override.Until,
+		}
+	}
 	return report
 }
 
+// sendReport never blocks: a report not yet consumed is replaced by
+// the newer one.
 func (i *interpoler) sendReport(r zeus.StateReport) {
-	select {
-	case i.reports <- r:
-	default:
+	for {
+		select {
+		case i.reports <- r:
+			return
+		default:
+		}
+		select {
+		case <-i.reports:
+		default:
+		}
 	}
 }
 
@@ -64,8 +101,11 @@ func (i *interpoler) sendState(s zeus.State) {
 }
 
 func (i *interpoler) Interpolate(ready chan<- struct{}) {
-	i.quit = make(chan struct{})
+	// Close() resets i.quit, the loop must keep its own reference.
+	quit := make(chan struct{})
+	i.quit = quit
 	defer func() {
+		close(i.done)
 		close(i.states)
 		close(i.reports)
 	}()
@@ -75,7 +115,13 @@ func (i *interpoler) Interpolate(ready chan<- struct{}) {
 	cur, nextTime, next := i.interpoler.CurrentInterpolation(now)
 	i.logger.Printf("Starting interpolation is %s", cur)
 
-	i.sendState(cur.State(now))
+	if zeus.IsStopped(cur) == true {
+		close(ready)
+		i.stop(cur, now, quit)
+		return
+	}
+
+	i.sendState(i.state(cur, now))
 
 	isTransition := cur.End() != nil
 
@@ -87,22 +133,62 @@ func (i *interpoler) Interpolate(ready chan<- struct{}) {
 	close(ready)
 	for {
 		select {
-		case <-i.quit:
+		case <-quit:
 			i.logger.Printf("Closing climate interpolation")
 			return
+		case climate := <-i.updates:
+			now := time.Now()
+			i.interpoler = climate
+			cur, nextTime, next = i.interpoler.CurrentInterpolation(now)
+			i.logger.Printf("Climate updated, interpolation is %s", cur)
+			if zeus.IsStopped(cur) == true {
+				i.stop(cur, now, quit)
+				return
+			}
+			isTransition = cur.End() != nil
+			i.sendState(i.state(cur, now))
+			i.sendReport(i.stateReport(cur, next, now, nextTime))
+		case o := <-i.overrides:
+			now := time.Now()
+			if o == nil {
+				i.logger.Printf("Override cleared")
+			} else {
+				i.logger.Printf("New %s", o)
+			}
+			i.override = o
+			cur, nextTime, next = i.interpoler.CurrentInterpolation(now)
+			if zeus.IsStopped(cur) == true {
+				i.stop(cur, now, quit)
+				return
+			}
+			isTransition = cur.End() != nil
+			i.sendState(i.state(cur, now))
+			i.sendReport(i.stateReport(cur, next, now, nextTime))
 		case now := <-timer.C:
 			new, nextTime, next := i.interpoler.CurrentInterpolation(now)
 			newIsTransition := new.End() != nil
 
-			if isTransition != newIsTransition {
+			if zeus.IsStopped(new) == true {
+				i.stop(new, now, quit)
+				return
+			}
+
+			overrideEnded := false
+			if i.override != nil && i.override.Active(now) == false {
+				i.logger.Printf("Override ended")
+				i.override = nil
+				overrideEnded = true
+			}
+
+			if isTransition != newIsTransition || (isTransition == false && new.String() != cur.String()) {
 				i.logger.Printf("New interpolation %s", new)
 				cur = new
 				isTransition = newIsTransition
-			} else if isTransition == false {
-				i.sendState(cur.State(now))
+			} else if isTransition == false && overrideEnded == false {
+				i.sendState(i.state(cur, now))
 				continue
 			}
-			s := cur.State(now)
+			s := i.state(cur, now)
 			i.sendState(s)
 			report := i.stateReport(cur, next, now, nextTime)
 			i.sendReport(report)
@@ -110,13 +196,23 @@ func (i *interpoler) Interpolate(ready chan<- struct{}) {
 	}
 }
 
-func NewInterpoler(name string, states []zeus.State, transitions []zeus.Transition) (Interpoler, error) {
+// stop reports the end of an experiment which stops all climate
+// control.
+func (i *interpoler) stop(cur zeus.Interpolation, now time.Time, quit <-chan struct{}) {
+	i.logger.Printf("Experiment ended: %s", cur)
+	select {
+	case i.reports <- i.stateReport(cur, nil, now, time.Time{}):
+	case <-quit:
+	}
+}
+
+func NewInterpoler(name string, climate zeus.ZoneClimate, reference time.Time) (Interpoler, error) {
 	hostname, err := os.Hostname()
 	if err != nil {
 		return nil, err
 	}
 	logger := log.New(os.Stderr, "[zone/"+name+"/climate]: ", 0)
-	i, err := zeus.NewClimateInterpoler(states, transitions, time.Now().UTC())
+	i, err := zeus.NewZoneClimateInterpoler(climate, reference.UTC())
 	if err != nil {
 		return nil, err
 	}
@@ -125,6 +221,9 @@ func NewInterpoler(name string, states []zeus.State, transitions []zeus.Transiti
 		name:       path.Join(hostname, "zone", name),
 		interpoler: i,
 		logger:     logger,
+		done:       make(chan struct{}),
+		updates:    make(chan zeus.ClimateInterpoler),
+		overrides:  make(chan *zeus.Override),
 		reports:    make(chan zeus.StateReport, 1),
 		states:     make(chan zeus.State, 1),
 		Period:     5 * time.Second,
@@ -139,6 +238,24 @@ func (i *interpoler) Reports() <-chan zeus.StateReport {
 	return i.reports
 }
 
+func (i *interpoler) Update(climate zeus.ClimateInterpoler) error {
+	select {
+	case i.updates <- climate:
+		return nil
+	case <-i.done:
+		return fmt.Errorf("interpolation is not running")
+	}
+}
+
+func (i *interpoler) Override(o *zeus.Override) error {
+	select {
+	case i.overrides <- o:
+		return nil
+	case <-i.done:
+		return fmt.Errorf("interpolation is not running")
+	}
+}
+
 func (i *interpoler) Close() error {
 	if i.quit == nil {
 		return fmt.Errorf("Already closed")
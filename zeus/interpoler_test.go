@@ -27,7 +27,7 @@ func (s *InterpolationManagerSuite) TestInterpolationReportsSanitized(c *C) {
 		},
 	}
 
-	i, err := NewInterpoler("test-zone", states, []zeus.Transition{})
+	i, err := NewInterpoler("test-zone", zeus.ZoneClimate{States: states}, time.Now())
 	c.Assert(err, IsNil)
 
 	i.(*interpoler).logger.SetOutput(bytes.NewBuffer(nil))
@@ -59,3 +59,112 @@ func (s *InterpolationManagerSuite) TestInterpolationReportsSanitized(c *C) {
 	wg.Wait()
 
 }
+
+func (s *InterpolationManagerSuite) TestInterpolationStopsAtExperimentEnd(c *C) {
+	states := []zeus.State{
+		zeus.State{
+			Name:         "only",
+			Temperature:  22.0,
+			Humidity:     zeus.UndefinedHumidity,
+			Wind:         zeus.UndefinedWind,
+			VisibleLight: zeus.UndefinedLight,
+			UVLight:      zeus.UndefinedLight,
+		},
+	}
+	reference := time.Now().Add(-48 * time.Hour)
+	end := &zeus.ExperimentEnd{Day: 1, Time: time.Date(0, 1, 1, 0, 0, 0, 0, time.UTC)}
+	i, err := NewInterpoler("test-zone", zeus.ZoneClimate{States: states, End: end}, reference)
+	c.Assert(err, IsNil)
+	i.(*interpoler).logger.SetOutput(bytes.NewBuffer(nil))
+
+	ready := make(chan struct{})
+	done := make(chan struct{})
+	go func() {
+		i.Interpolate(ready)
+		close(done)
+	}()
+	<-ready
+
+	r, ok := <-i.Reports()
+	c.Assert(ok, Equals, true)
+	c.Check(r.Current.Name, Equals, zeus.StoppedStateName)
+	c.Check(r.Ended, Equals, true)
+	c.Assert(r.End, Not(IsNil))
+	c.Check(r.Next, IsNil)
+
+	// the interpolation stops by itself
+	select {
+	case <-done:
+	case <-time.After(time.Second):
+		c.Fatalf("interpolation did not stop")
+	}
+	_, ok = <-i.States()
+	c.Check(ok, Equals, false)
+}
+
+func (s *InterpolationManagerSuite) TestOverride(c *C) {
+	states := []zeus.State{
+		zeus.State{
+			Name:         "only",
+			Temperature:  22.0,
+			Humidity:     50.0,
+			Wind:         zeus.UndefinedWind,
+			VisibleLight: zeus.UndefinedLight,
+			UVLight:      zeus.UndefinedLight,
+		},
+	}
+	i, err := NewInterpoler("test-zone", zeus.ZoneClimate{States: states}, time.Now())
+	c.Assert(err, IsNil)
+	i.(*interpoler).logger.SetOutput(bytes.NewBuffer(nil))
+	i.(*interpoler).Period = 1 * time.Millisecond
+
+	ready := make(chan struct{})
+	go i.Interpolate(ready)
+	<-ready
+	go func() {
+		for range i.States() {
+		}
+	}()
+
+	r := <-i.Reports()
+	c.Check(r.Override, IsNil)
+
+	partial := zeus.State{
+		Temperature:  zeus.UndefinedTemperature,
+		Humidity:     70.0,
+		Wind:         zeus.UndefinedWind,
+		VisibleLight: zeus.UndefinedLight,
+		UVLight:      zeus.UndefinedLight,
+	}
+	o, err := zeus.NewOverride(partial, time.Now(), 50*time.Millisecond)
+	c.Assert(err, IsNil)
+	c.Assert(i.Override(o), IsNil)
+	r = <-i.Reports()
+	c.Check(r.Current.Name, Equals, "only")
+	c.Check(r.Current.Temperature, Equals, zeus.Temperature(22.0))
+	c.Check(r.Current.Humidity, Equals, zeus.Humidity(70.0))
+	c.Assert(r.Override, Not(IsNil))
+	c.Check(r.Override.Until, Equals, o.Until)
+
+	// the override ends by itself
+	select {
+	case r = <-i.Reports():
+		c.Check(r.Override, IsNil)
+		c.Check(r.Current.Humidity, Equals, zeus.Humidity(50.0))
+	case <-time.After(time.Second):
+		c.Fatalf("override did not end")
+	}
+
+	o, err = zeus.NewOverride(partial, time.Now(), time.Hour)
+	c.Assert(err, IsNil)
+	c.Assert(i.Override(o), IsNil)
+	r = <-i.Reports()
+	c.Check(r.Override, Not(IsNil))
+	c.Assert(i.Override(nil), IsNil)
+	r = <-i.Reports()
+	c.Check(r.Override, IsNil)
+	c.Check(r.Current.Humidity, Equals, zeus.Humidity(50.0))
+
+	c.Check(i.Close(), IsNil)
+	c.Check(i.Override(nil), ErrorMatches, "interpolation is not running")
+}
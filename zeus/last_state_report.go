@@ -21,6 +21,9 @@ func (r *lastStateReporter) Report(ready chan<- struct{}) {
 				r.states = nil
 			} else {
 				r.last.State = zeus.SanitizeState(s.Current)
+				r.last.End = s.End
+				r.last.Ended = s.Ended
+				r.last.Override = s.Override
 			}
 		case report, ok := <-r.climates:
 			if ok == false {
@@ -29,13 +32,20 @@ func (r *lastStateReporter) Report(ready chan<- struct{}) {
 				r.last.Humidity = report.Humidity.Value()
 				if len(report.Temperatures) > 0 {
 					r.last.Temperature = report.Temperatures[0].Value()
+					r.last.VaporPressureDeficit = report.VaporPressureDeficit()
+					r.last.AbsoluteHumidity = report.AbsoluteHumidity()
 				}
 			}
 		case req := <-r.requests:
 			req <- zeus.ZeusZoneStatus{
-				Temperature: r.last.Temperature,
-				Humidity:    r.last.Humidity,
-				State:       zeus.SanitizeState(r.last.State),
+				Temperature:          r.last.Temperature,
+				Humidity:             r.last.Humidity,
+				VaporPressureDeficit: r.last.VaporPressureDeficit,
+				AbsoluteHumidity:     r.last.AbsoluteHumidity,
+				State:                zeus.SanitizeState(r.last.State),
+				End:                  r.last.End,
+				Ended:                r.last.Ended,
+				Override:             r.last.Override,
 			}
 		}
 
@@ -25,6 +25,10 @@ func (c *SimulateCommand) Execute(args []string) error {
 	if err != nil {
 		return err
 	}
+	season.Zones, err = season.ResolveZones()
+	if err != nil {
+		return err
+	}
 
 	s, err := NewZeusSimulator(ZeusSimulatorArgs{
 		hostname:       c.Args.Hostname,
@@ -15,6 +15,9 @@ type slackReporter struct {
 	zoneName string
 	hostName string
 	events   chan zeus.AlarmEvent
+	states   chan zeus.StateReport
+
+	endAnnounced, endReported bool
 }
 
 func (r *slackReporter) formatEvent(e zeus.AlarmEvent) string {
@@ -24,23 +27,70 @@ func (r *slackReporter) formatEvent(e zeus.AlarmEvent) string {
 		icon = ":warning:"
 		alarmText = "alarm is on!"
 	}
+	if len(e.Description) > 0 {
+		return fmt.Sprintf("%s %s.%s : '%s' (%s) %s", icon, r.hostName, e.ZoneIdentifier, e.Reason, e.Description, alarmText)
+	}
 	return fmt.Sprintf("%s %s.%s : '%s' %s", icon, r.hostName, e.ZoneIdentifier, e.Reason, alarmText)
 }
 
-func (r *slackReporter) Report(ready chan<- struct{}) {
-	r.c.PostMessage(r.userID, slack.MsgOptionText(fmt.Sprintf(":ok: climate control on %s.%s started.", r.hostName, r.zoneName), true))
-	close(ready)
-	for e := range r.events {
-		if e.Flags&zeus.InstantNotification == 0 {
-			continue
+// formatEnd returns the message to post for the end of the
+// experiment in report, or an empty string if there is nothing new
+// to notify.
+func (r *slackReporter) formatEnd(report zeus.StateReport) string {
+	if report.End == nil {
+		return ""
+	}
+	if report.Ended == true {
+		if r.endReported == true {
+			return ""
 		}
-		if e.ZoneIdentifier != zeus.ZoneIdentifier(r.hostName, r.zoneName) {
-			continue
+		r.endReported = true
+		r.endAnnounced = true
+		if report.Current.Name == zeus.StoppedStateName {
+			return fmt.Sprintf(":checkered_flag: experiment on %s.%s ended, climate control stopped.", r.hostName, r.zoneName)
 		}
+		return fmt.Sprintf(":checkered_flag: experiment on %s.%s ended, climate is now '%s'.", r.hostName, r.zoneName, report.Current.Name)
+	}
+	if r.endAnnounced == true {
+		return ""
+	}
+	r.endAnnounced = true
+	return fmt.Sprintf(":calendar: experiment on %s.%s will end on %s.", r.hostName, r.zoneName, report.End.Format("Mon Jan 02 15:04 MST 2006"))
+}
 
-		_, _, err := r.c.PostMessage(r.userID, slack.MsgOptionText(r.formatEvent(e), true))
-		if err != nil {
-			fmt.Fprintf(os.Stderr, "[zone/%s/slack] cannot notify alarm: %s\n", r.zoneName, err)
+func (r *slackReporter) post(text string) {
+	_, _, err := r.c.PostMessage(r.userID, slack.MsgOptionText(text, true))
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "[zone/%s/slack] cannot notify: %s\n", r.zoneName, err)
+	}
+}
+
+func (r *slackReporter) Report(ready chan<- struct{}) {
+	r.c.PostMessage(r.userID, slack.MsgOptionText(fmt.Sprintf(":ok: climate control on %s.%s started.", r.hostName, r.zoneName), true))
+	close(ready)
+	events, states := r.events, r.states
+	for events != nil || states != nil {
+		select {
+		case e, ok := <-events:
+			if ok == false {
+				events = nil
+				continue
+			}
+			if e.Flags&zeus.InstantNotification == 0 {
+				continue
+			}
+			if e.ZoneIdentifier != zeus.ZoneIdentifier(r.hostName, r.zoneName) {
+				continue
+			}
+			r.post(r.formatEvent(e))
+		case report, ok := <-states:
+			if ok == false {
+				states = nil
+				continue
+			}
+			if text := r.formatEnd(report); len(text) > 0 {
+				r.post(text)
+			}
 		}
 	}
 	r.c.PostMessage(r.userID, slack.MsgOptionText(fmt.Sprintf(":ok: climate control on %s.%s stopped.", r.hostName, r.zoneName), true))
@@ -50,6 +100,10 @@ func (r *slackReporter) AlarmChannel() chan<- zeus.AlarmEvent {
 	return r.events
 }
 
+func (r *slackReporter) StateChannel() chan<- zeus.StateReport {
+	return r.states
+}
+
 func FindSlackUser(c *slack.Client, username string) (string, error) {
 	username = strings.TrimPrefix(username, "@")
 	users, err := c.GetUsers()
@@ -64,12 +118,13 @@ func FindSlackUser(c *slack.Client, username string) (string, error) {
 	return "", fmt.Errorf("Could not find user @%s on slack", username)
 }
 
-func NewSlackReporter(c *slack.Client, userID, zoneName string) (AlarmReporter, error) {
+func NewSlackReporter(c *slack.Client, userID, zoneName string) (*slackReporter, error) {
 	res := &slackReporter{
 		c:        c,
 		userID:   userID,
 		zoneName: zoneName,
 		events:   make(chan zeus.AlarmEvent),
+		states:   make(chan zeus.StateReport, 1),
 	}
 	var err error
 	res.hostName, err = os.Hostname()
@@ -4,19 +4,24 @@ import (
 	"bytes"
 	"context"
 	"fmt"
+	"io/ioutil"
 	"log"
 	"net/http"
 	"net/rpc"
 	"os"
 	"path/filepath"
+	"reflect"
+	"sort"
 	"sync"
 	"time"
 
 	"github.com/adrg/xdg"
 	socketcan "github.com/atuleu/golang-socketcan"
+	"github.com/formicidae-tracker/libarke/src-go/arke"
 	"github.com/formicidae-tracker/zeus"
 	"github.com/grandcat/zeroconf"
 	"github.com/slack-go/slack"
+	yaml "gopkg.in/yaml.v2"
 )
 
 type Zeus struct {
@@ -29,6 +34,7 @@ type Zeus struct {
 	definitions map[string]ZoneDefinition
 
 	dispatchers map[string]ArkeDispatcher
+	heartbeats  map[string]*heartbeatRecord
 	runners     map[string]ZoneClimateRunner
 	since       time.Time
 
@@ -51,6 +57,7 @@ func OpenZeus(c Config) (*Zeus, error) {
 		definitions: c.Zones,
 		runners:     make(map[string]ZoneClimateRunner),
 		dispatchers: make(map[string]ArkeDispatcher),
+		heartbeats:  make(map[string]*heartbeatRecord),
 	}
 	if len(c.SlackToken) > 0 {
 		z.logger.Printf("Slack notification are enabled")
@@ -154,16 +161,42 @@ func (z *Zeus) dispatcherForInterface(ifname string) (ArkeDispatcher, error) {
 	return d, nil
 }
 
-func (z *Zeus) checkSeason(season zeus.SeasonFile) error {
-	for zoneName, _ := range season.Zones {
+// heartbeatsForInterface returns the heartbeats received on an
+// interface, across all the seasons it was opened for.
+func (z *Zeus) heartbeatsForInterface(ifname string) *heartbeatRecord {
+	r, ok := z.heartbeats[ifname]
+	if ok == false {
+		r = newHeartbeatRecord()
+		z.heartbeats[ifname] = r
+	}
+	return r
+}
+
+// checkSeason returns the climate of each zone of season, with the
+// followed schedules resolved, or an error if the zones cannot run
+// it.
+func (z *Zeus) checkSeason(season zeus.SeasonFile) (map[string]zeus.ZoneClimate, error) {
+	for zoneName := range season.Zones {
 		if z.hasZone(zoneName) == false {
-			return fmt.Errorf("missing zone '%s' %+v", zoneName, z.definitions)
+			return nil, fmt.Errorf("missing zone '%s' %+v", zoneName, z.definitions)
 		}
 	}
-	return nil
+	zones, err := season.ResolveZones()
+	if err != nil {
+		return nil, err
+	}
+	for zoneName, climate := range zones {
+		if err := z.definitions[zoneName].HardwareLimits().CheckClimate(climate); err != nil {
+			return nil, fmt.Errorf("zone '%s': %s", zoneName, err)
+		}
+		if err := checkDrivenChannels(climate, z.definitions[zoneName]); err != nil {
+			return nil, fmt.Errorf("zone '%s': %s", zoneName, err)
+		}
+	}
+	return zones, nil
 }
 
-func (z *Zeus) setupZoneClimate(name, suffix string, definition ZoneDefinition, climate zeus.ZoneClimate, userID string) error {
+func (z *Zeus) setupZoneClimate(name, suffix string, definition ZoneDefinition, climate zeus.ZoneClimate, userID string, resume bool) error {
 	d, err := z.dispatcherForInterface(definition.CANInterface)
 	if err != nil {
 		return err
@@ -171,7 +204,10 @@ func (z *Zeus) setupZoneClimate(name, suffix string, definition ZoneDefinition,
 	r, err := NewZoneClimateRunner(ZoneClimateRunnerOptions{
 		Name:        name,
 		FileSuffix:  suffix,
+		Reference:   z.since,
+		ResumeLogs:  resume,
 		Dispatcher:  d,
+		Heartbeats:  z.heartbeatsForInterface(definition.CANInterface),
 		Climate:     climate,
 		OlympusHost: z.olympusHost,
 		Definition:  definition,
@@ -185,7 +221,11 @@ func (z *Zeus) setupZoneClimate(name, suffix string, definition ZoneDefinition,
 	return nil
 }
 
-func (z *Zeus) startClimate(season zeus.SeasonFile) (rerr error) {
+func (z *Zeus) startClimate(season zeus.SeasonFile) error {
+	return z.startClimateSince(season, time.Now(), false)
+}
+
+func (z *Zeus) startClimateSince(season zeus.SeasonFile, since time.Time, resume bool) (rerr error) {
 	if z.isRunning() == true {
 		return fmt.Errorf("Already started")
 	}
@@ -198,15 +238,15 @@ func (z *Zeus) startClimate(season zeus.SeasonFile) (rerr error) {
 		z.reset()
 	}()
 
-	if err := z.checkSeason(season); err != nil {
+	zones, err := z.checkSeason(season)
+	if err != nil {
 		return fmt.Errorf("invalid season file: %s", err)
 	}
-	z.since = time.Now()
+	z.since = since
 	suffix := z.since.Format("2006-01-02T150405")
 	userID := ""
 
 	if z.slackClient != nil && len(season.SlackUser) > 0 {
-		var err error
 		userID, err = FindSlackUser(z.slackClient, season.SlackUser)
 		if err != nil {
 			return err
@@ -214,8 +254,8 @@ func (z *Zeus) startClimate(season zeus.SeasonFile) (rerr error) {
 		z.logger.Printf("Will report to %s:%s", season.SlackUser, userID)
 	}
 
-	for name, climate := range season.Zones {
-		err := z.setupZoneClimate(name, suffix, z.definitions[name], climate, userID)
+	for name, climate := range zones {
+		err := z.setupZoneClimate(name, suffix, z.definitions[name], climate, userID, resume)
 		if err != nil {
 			return fmt.Errorf("Could not setup zone '%s': %s", name, err)
 		}
@@ -233,7 +273,7 @@ func (z *Zeus) startClimate(season zeus.SeasonFile) (rerr error) {
 		go r.Run()
 	}
 
-	z.saveStaticState(season)
+	z.saveStaticState(staticState{Since: z.since, Season: season})
 
 	return nil
 }
@@ -278,20 +318,186 @@ func (z *Zeus) stopClimate() error {
 	return nil
 }
 
+func checkClientVersion(version string) error {
+	compatible, err := zeus.VersionAreCompatible(zeus.ZEUS_VERSION, version)
+	if err != nil {
+		return err
+	}
+	if compatible == false {
+		return fmt.Errorf("client version (%s) is incompatible with service version (%s)", version, zeus.ZEUS_VERSION)
+	}
+	return nil
+}
+
 func (z *Zeus) StartClimate(args zeus.ZeusStartArgs, unused *int) error {
 	z.mx.Lock()
 	defer z.mx.Unlock()
 
-	compatible, err := zeus.VersionAreCompatible(zeus.ZEUS_VERSION, args.Version)
+	if err := checkClientVersion(args.Version); err != nil {
+		return err
+	}
+
+	return z.startClimate(args.Season)
+}
+
+func (z *Zeus) updateClimate(season zeus.SeasonFile) error {
+	if z.isRunning() == false {
+		return fmt.Errorf("Not running")
+	}
+	zones, err := z.checkSeason(season)
 	if err != nil {
+		return fmt.Errorf("invalid season file: %s", err)
+	}
+	for name := range z.runners {
+		if _, ok := season.Zones[name]; ok == false {
+			return fmt.Errorf("invalid season file: running zone '%s' is missing", name)
+		}
+	}
+	for name, climate := range zones {
+		if _, ok := z.runners[name]; ok == false {
+			return fmt.Errorf("invalid season file: zone '%s' is not running", name)
+		}
+		if err := z.runners[name].CheckClimate(climate); err != nil {
+			return fmt.Errorf("Could not update zone '%s': %s", name, err)
+		}
+	}
+
+	for name, climate := range zones {
+		if err := z.runners[name].UpdateClimate(climate); err != nil {
+			return fmt.Errorf("Could not update zone '%s': %s", name, err)
+		}
+	}
+
+	z.logger.Printf("Climate updated")
+	z.saveStaticState(staticState{Since: z.since, Season: season})
+	return nil
+}
+
+// UpdateClimate replaces the season of the running experiment, without
+// stopping climate control.
+func (z *Zeus) UpdateClimate(args zeus.ZeusStartArgs, unused *int) error {
+	z.mx.Lock()
+	defer z.mx.Unlock()
+
+	if err := checkClientVersion(args.Version); err != nil {
 		return err
 	}
 
-	if compatible == false {
-		return fmt.Errorf("client version (%s) is incompatible with service version (%s)", args.Version, zeus.ZEUS_VERSION)
+	return z.updateClimate(args.Season)
+}
+
+// validateSeason describes how season would start, without touching
+// the bus nor the log files.
+func (z *Zeus) validateSeason(season zeus.SeasonFile) (*zeus.ZeusValidationReply, error) {
+	zones, err := z.checkSeason(season)
+	if err != nil {
+		return nil, fmt.Errorf("invalid season file: %s", err)
+	}
+	reply := &zeus.ZeusValidationReply{Zones: make(map[string]zeus.ZeusZoneValidation)}
+	if z.isRunning() == true {
+		reply.Errors = append(reply.Errors, "climate is already running")
+	}
+	if z.slackClient != nil && len(season.SlackUser) > 0 {
+		reply.SlackUser = season.SlackUser
+		reply.SlackUserID, err = FindSlackUser(z.slackClient, season.SlackUser)
+		if err != nil {
+			reply.Errors = append(reply.Errors, err.Error())
+		}
 	}
 
-	return z.startClimate(args.Season)
+	names := make([]string, 0, len(zones))
+	for name := range zones {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		definition := z.definitions[name]
+		if _, err := zeus.NewZoneClimateInterpoler(zones[name], time.Now()); err != nil {
+			reply.Errors = append(reply.Errors, fmt.Sprintf("zone '%s': %s", name, err))
+		}
+		// a running zone always records its climate in its log
+		capabilities := computeCapabilities(zones[name], definition, true, nil)
+		validation := zeus.ZeusZoneValidation{
+			Interface: definition.CANInterface,
+			Olympus:   z.olympusHost,
+		}
+		// heartbeats are only received while the interface is open
+		_, monitored := z.dispatchers[definition.CANInterface]
+		// a dry run does not create a record for an unused interface
+		heartbeats := z.heartbeats[definition.CANInterface]
+		ID := arke.NodeID(definition.DevicesID)
+		listed := make(map[arke.NodeClass]bool)
+		for _, c := range capabilities {
+			validation.Capabilities = append(validation.Capabilities, reflect.TypeOf(c).Elem().Name())
+			for _, class := range c.Requirements() {
+				if listed[class] == true {
+					continue
+				}
+				listed[class] = true
+				validation.Devices = append(validation.Devices, zeus.ZeusDeviceStatus{
+					Class:         class,
+					ID:            ID,
+					Monitored:     monitored,
+					LastHeartbeat: heartbeats.Last(class, ID),
+				})
+			}
+		}
+		reply.Zones[name] = validation
+	}
+	return reply, nil
+}
+
+// ValidateSeason reports how a season would start, without starting
+// it.
+func (z *Zeus) ValidateSeason(args zeus.ZeusStartArgs, reply *zeus.ZeusValidationReply) error {
+	z.mx.Lock()
+	defer z.mx.Unlock()
+
+	if err := checkClientVersion(args.Version); err != nil {
+		return err
+	}
+	res, err := z.validateSeason(args.Season)
+	if err != nil {
+		return err
+	}
+	*reply = *res
+	return nil
+}
+
+func (z *Zeus) runner(zoneName string) (ZoneClimateRunner, error) {
+	if z.isRunning() == false {
+		return nil, fmt.Errorf("Not running")
+	}
+	r, ok := z.runners[zoneName]
+	if ok == false {
+		return nil, fmt.Errorf("unknown zone '%s'", zoneName)
+	}
+	return r, nil
+}
+
+// OverrideZone forces some climate values of a zone for a given
+// duration.
+func (z *Zeus) OverrideZone(args zeus.ZeusOverrideArgs, unused *int) error {
+	z.mx.Lock()
+	defer z.mx.Unlock()
+
+	r, err := z.runner(args.ZoneName)
+	if err != nil {
+		return err
+	}
+	return r.Override(args.State, args.Duration)
+}
+
+// ClearOverride resumes the schedule of an overridden zone.
+func (z *Zeus) ClearOverride(zoneName string, unused *int) error {
+	z.mx.Lock()
+	defer z.mx.Unlock()
+
+	r, err := z.runner(zoneName)
+	if err != nil {
+		return err
+	}
+	return r.ClearOverride()
 }
 
 func (z *Zeus) StopClimate(ignored int, unused *int) error {
@@ -311,6 +517,10 @@ func (z *Zeus) Status(ignored int, reply *zeus.ZeusStatusReply) error {
 
 	reply.Running = z.isRunning()
 	reply.Version = zeus.ZEUS_VERSION
+	reply.Limits = make(map[string]zeus.HardwareLimits)
+	for n, d := range z.definitions {
+		reply.Limits[n] = d.HardwareLimits()
+	}
 
 	if reply.Running == false {
 		return nil
@@ -363,30 +573,52 @@ func (z *Zeus) AlarmLog(args zeus.ZeusLogArgs, reply *zeus.ZeusAlarmLogReply) er
 	return err
 }
 
+// staticState is saved on disk while climate is running, in order to
+// resume the same experiment if the service is restarted.
+type staticState struct {
+	Since  time.Time       `yaml:"since"`
+	Season zeus.SeasonFile `yaml:"season"`
+}
+
 func (z *Zeus) stateFilePath() (string, error) {
+	return xdg.DataFile("fort-experiments/climate/current.state")
+}
+
+// legacyStateFilePath is the file used by previous versions, which
+// only contains the season file without its starting date.
+func (z *Zeus) legacyStateFilePath() (string, error) {
 	return xdg.DataFile("fort-experiments/climate/current.season")
 }
 
-func (z *Zeus) saveStaticStateUnsafe(season zeus.SeasonFile) error {
+func (z *Zeus) saveStaticStateUnsafe(state staticState) error {
 	fpath, err := z.stateFilePath()
 	if err != nil {
 		return err
 	}
-	return season.WriteFile(fpath)
+	data, err := yaml.Marshal(state)
+	if err != nil {
+		return err
+	}
+	return ioutil.WriteFile(fpath, data, 0644)
 }
 
-func (z *Zeus) saveStaticState(season zeus.SeasonFile) {
-	if err := z.saveStaticStateUnsafe(season); err != nil {
+func (z *Zeus) saveStaticState(state staticState) {
+	if err := z.saveStaticStateUnsafe(state); err != nil {
 		z.logger.Printf("could not save state: %s", err)
 	}
 }
 
 func (z *Zeus) clearStaticStateUnsafe() error {
-	filename, err := z.stateFilePath()
-	if err != nil {
-		return err
+	for _, path := range []func() (string, error){z.stateFilePath, z.legacyStateFilePath} {
+		filename, err := path()
+		if err != nil {
+			return err
+		}
+		if err := os.RemoveAll(filename); err != nil {
+			return err
+		}
 	}
-	return os.RemoveAll(filename)
+	return nil
 }
 
 func (z *Zeus) clearStaticState() {
@@ -395,11 +627,36 @@ func (z *Zeus) clearStaticState() {
 	}
 }
 
-func (z *Zeus) restoreStaticStateUnsafe() error {
+func (z *Zeus) readStaticState() (*staticState, error) {
 	filename, err := z.stateFilePath()
 	if err != nil {
-		return err
+		return nil, err
+	}
+	data, err := ioutil.ReadFile(filename)
+	if err == nil {
+		state := &staticState{}
+		if err := yaml.Unmarshal(data, state); err != nil {
+			return nil, err
+		}
+		return state, nil
+	}
+	if os.IsNotExist(err) == false {
+		return nil, err
+	}
+
+	filename, err = z.legacyStateFilePath()
+	if err != nil {
+		return nil, err
+	}
+	season, err := zeus.ReadSeasonFile(filename, bytes.NewBuffer(nil))
+	if err != nil {
+		return nil, err
 	}
+	z.logger.Printf("restoring legacy state without starting date")
+	return &staticState{Since: time.Now(), Season: *season}, nil
+}
+
+func (z *Zeus) restoreStaticStateUnsafe() (err error) {
 	defer func() {
 		if err == nil {
 			return
@@ -407,17 +664,15 @@ func (z *Zeus) restoreStaticStateUnsafe() error {
 		z.logger.Printf("clearing invalid state")
 		z.clearStaticState()
 	}()
-	var season *zeus.SeasonFile = nil
-	season, err = zeus.ReadSeasonFile(filename, bytes.NewBuffer(nil))
+	state, err := z.readStaticState()
 	if err != nil {
 		if os.IsNotExist(err) {
-			err = nil
 			return nil
 		}
 		return err
 	}
-	err = z.startClimate(*season)
-	return err
+	z.logger.Printf("restoring experiment started at %s", state.Since)
+	return z.startClimateSince(state.Season, state.Since, true)
 }
 
 func (z *Zeus) restoreStaticState() {
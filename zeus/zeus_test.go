@@ -5,11 +5,13 @@ import (
 	"io/ioutil"
 	"net/http"
 	"os"
+	"path/filepath"
 	"sync"
 	"time"
 
 	"github.com/adrg/xdg"
 	socketcan "github.com/atuleu/golang-socketcan"
+	"github.com/formicidae-tracker/libarke/src-go/arke"
 	"github.com/formicidae-tracker/zeus"
 	. "gopkg.in/check.v1"
 )
@@ -130,3 +132,335 @@ func (s *ZeusSuite) TestStartStop(c *C) {
 	c.Check(s.zeus.startClimate(zeus.SeasonFile{}), ErrorMatches, "Already started")
 	c.Check(s.zeus.stopClimate(), IsNil)
 }
+
+func (s *ZeusSuite) TestRestoreKeepsExperimentReference(c *C) {
+	season := zeus.SeasonFile{
+		Zones: map[string]zeus.ZoneClimate{
+			"nest": zeus.ZoneClimate{
+				States: []zeus.State{
+					zeus.State{
+						Name:         "day",
+						Temperature:  26.0,
+						Humidity:     50,
+						Wind:         100,
+						VisibleLight: 100,
+						UVLight:      100,
+					},
+				},
+			},
+		},
+	}
+	since := time.Now().Add(-72 * time.Hour).Round(0)
+	c.Assert(s.zeus.startClimateSince(season, since, false), IsNil)
+	climateLog := s.zeus.runners["nest"].(*zoneClimateRunner).climateLog
+	alarmLog := s.zeus.runners["nest"].(*zoneClimateRunner).alarmLog
+	// simulates a crash: the static state is not cleared.
+	s.zeus.closeRunners()
+	s.zeus.closeDispatchers()
+	s.zeus.reset()
+
+	c.Assert(s.zeus.restoreStaticStateUnsafe(), IsNil)
+	c.Check(s.zeus.isRunning(), Equals, true)
+	c.Check(s.zeus.since.Equal(since), Equals, true, Commentf("restored %s, expected %s", s.zeus.since, since))
+
+	c.Check(s.zeus.runners["nest"].(*zoneClimateRunner).climateLog, Equals, climateLog)
+	c.Check(s.zeus.runners["nest"].(*zoneClimateRunner).alarmLog, Equals, alarmLog)
+
+	c.Check(s.zeus.stopClimate(), IsNil)
+	c.Check(s.zeus.restoreStaticStateUnsafe(), IsNil)
+	c.Check(s.zeus.isRunning(), Equals, false)
+}
+
+func (s *ZeusSuite) TestUpdateClimate(c *C) {
+	day := zeus.State{
+		Name:         "day",
+		Temperature:  26.0,
+		Humidity:     50,
+		Wind:         100,
+		VisibleLight: zeus.UndefinedLight,
+		UVLight:      zeus.UndefinedLight,
+	}
+	season := zeus.SeasonFile{
+		Zones: map[string]zeus.ZoneClimate{
+			"nest": zeus.ZoneClimate{
+				MinimalTemperature: 20.0,
+				MaximalTemperature: 30.0,
+				MinimalHumidity:    40.0,
+				MaximalHumidity:    60.0,
+				States:             []zeus.State{day},
+			},
+		},
+	}
+	c.Check(s.zeus.updateClimate(season), ErrorMatches, "Not running")
+
+	since := time.Now().Add(-48 * time.Hour).Round(0)
+	c.Assert(s.zeus.startClimateSince(season, since, false), IsNil)
+	defer s.zeus.stopClimate()
+	runner := s.zeus.runners["nest"].(*zoneClimateRunner)
+
+	updated := season
+	updated.Zones = map[string]zeus.ZoneClimate{"nest": season.Zones["nest"]}
+	nest := updated.Zones["nest"]
+	nest.MaximalTemperature = 32.0
+	nest.States = []zeus.State{day}
+	nest.States[0].Temperature = 28.0
+	updated.Zones["nest"] = nest
+	c.Assert(s.zeus.updateClimate(updated), IsNil)
+
+	c.Check(s.zeus.since.Equal(since), Equals, true)
+	c.Check(s.zeus.runners["nest"], Equals, runner)
+	state, err := s.zeus.readStaticState()
+	c.Assert(err, IsNil)
+	c.Check(state.Since.Equal(since), Equals, true)
+	c.Check(state.Season.Zones["nest"].MaximalTemperature, Equals, zeus.Temperature(32.0))
+	for _, capability := range runner.capabilities {
+		if recordable, ok := capability.(*ClimateRecordable); ok == true {
+			c.Check(recordable.MaxTemperature, Equals, zeus.Temperature(32.0))
+		}
+	}
+
+	deadline := time.Now().Add(time.Second)
+	recorded := false
+	for recorded == false && time.Now().Before(deadline) {
+		events, err := ReadAlarmLogFile(runner.alarmLog)
+		c.Assert(err, IsNil)
+		for _, e := range events {
+			if e.Reason == zeus.ClimateUpdated.Reason() && e.Status == zeus.AlarmOn {
+				recorded = true
+			}
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+	c.Check(recorded, Equals, true, Commentf("climate update not found in alarm log"))
+
+	lighted := updated
+	lighted.Zones = map[string]zeus.ZoneClimate{"nest": updated.Zones["nest"]}
+	nest = lighted.Zones["nest"]
+	nest.States = []zeus.State{day}
+	nest.States[0].VisibleLight = 50
+	lighted.Zones["nest"] = nest
+	c.Check(s.zeus.updateClimate(lighted), ErrorMatches, "Could not update zone 'nest': new climate requires capabilities .*")
+
+	added := updated
+	added.Zones = map[string]zeus.ZoneClimate{"nest": updated.Zones["nest"], "tunnel": updated.Zones["nest"]}
+	c.Check(s.zeus.updateClimate(added), ErrorMatches, "invalid season file: zone 'tunnel' is not running")
+
+	c.Check(s.zeus.updateClimate(zeus.SeasonFile{}), ErrorMatches, "invalid season file: running zone 'nest' is missing")
+}
+
+func (s *ZeusSuite) TestValidateSeason(c *C) {
+	season := zeus.SeasonFile{
+		Zones: map[string]zeus.ZoneClimate{
+			"nest": zeus.ZoneClimate{
+				States: []zeus.State{
+					zeus.State{
+						Name:         "day",
+						Temperature:  26.0,
+						Humidity:     50,
+						Wind:         100,
+						VisibleLight: zeus.UndefinedLight,
+						UVLight:      zeus.UndefinedLight,
+					},
+				},
+			},
+		},
+	}
+	args := zeus.ZeusStartArgs{Version: zeus.ZEUS_VERSION, Season: season}
+	reply := zeus.ZeusValidationReply{}
+	c.Assert(s.zeus.ValidateSeason(args, &reply), IsNil)
+	c.Check(reply.Errors, HasLen, 0)
+	c.Check(reply.Zones["nest"].Interface, Equals, "slcan0")
+	c.Check(reply.Zones["nest"].Capabilities, DeepEquals, []string{"ClimateRecordable", "ClimateControllable"})
+	c.Check(reply.Zones["nest"].Devices, DeepEquals, []zeus.ZeusDeviceStatus{
+		{Class: arke.ZeusClass, ID: 1},
+		{Class: arke.CelaenoClass, ID: 1},
+	})
+	// neither the bus, its heartbeat records nor the logs are
+	// touched
+	c.Check(s.interfaces["slcan0"], HasLen, 0)
+	c.Check(s.zeus.heartbeats, HasLen, 0)
+	logs, err := ioutil.ReadDir(filepath.Join(s.dataDir, "fort-experiments/climate"))
+	c.Assert(err, IsNil)
+	c.Check(logs, HasLen, 0)
+	c.Check(s.zeus.isRunning(), Equals, false)
+
+	invalid := args
+	invalid.Season = zeus.SeasonFile{Zones: map[string]zeus.ZoneClimate{"box": season.Zones["nest"]}}
+	c.Check(s.zeus.ValidateSeason(invalid, &reply), ErrorMatches, "invalid season file: missing zone 'box'.*")
+
+	c.Assert(s.zeus.startClimate(season), IsNil)
+	reply = zeus.ZeusValidationReply{}
+	c.Assert(s.zeus.ValidateSeason(args, &reply), IsNil)
+	c.Check(reply.Errors, DeepEquals, []string{"climate is already running"})
+	c.Check(reply.Zones["nest"].Devices[0].Monitored, Equals, true)
+
+	// heartbeats are still reported once the climate is stopped
+	last := time.Now().Round(0)
+	s.zeus.heartbeatsForInterface("slcan0").Record(arke.CelaenoClass, 1, last)
+	c.Assert(s.zeus.stopClimate(), IsNil)
+	reply = zeus.ZeusValidationReply{}
+	c.Assert(s.zeus.ValidateSeason(args, &reply), IsNil)
+	c.Check(reply.Zones["nest"].Devices, DeepEquals, []zeus.ZeusDeviceStatus{
+		{Class: arke.ZeusClass, ID: 1},
+		{Class: arke.CelaenoClass, ID: 1, LastHeartbeat: &last},
+	})
+}
+
+func (s *ZeusSuite) TestOverrideZone(c *C) {
+	season := zeus.SeasonFile{
+		Zones: map[string]zeus.ZoneClimate{
+			"nest": zeus.ZoneClimate{
+				States: []zeus.State{
+					zeus.State{
+						Name:         "day",
+						Temperature:  26.0,
+						Humidity:     50,
+						Wind:         100,
+						VisibleLight: zeus.UndefinedLight,
+						UVLight:      zeus.UndefinedLight,
+					},
+				},
+			},
+		},
+	}
+	unused := 0
+	args := zeus.ZeusOverrideArgs{
+		ZoneName: "nest",
+		State: zeus.State{
+			Temperature:  zeus.UndefinedTemperature,
+			Humidity:     70,
+			Wind:         zeus.UndefinedWind,
+			VisibleLight: zeus.UndefinedLight,
+			UVLight:      zeus.UndefinedLight,
+		},
+		Duration: time.Hour,
+	}
+	c.Check(s.zeus.OverrideZone(args, &unused), ErrorMatches, "Not running")
+
+	c.Assert(s.zeus.startClimate(season), IsNil)
+	defer s.zeus.stopClimate()
+
+	c.Check(s.zeus.ClearOverride("nest", &unused), ErrorMatches, "climate is not overridden")
+	c.Check(s.zeus.OverrideZone(zeus.ZeusOverrideArgs{ZoneName: "foo"}, &unused), ErrorMatches, "unknown zone 'foo'")
+
+	lighted := args
+	lighted.State.VisibleLight = 100
+	c.Check(s.zeus.OverrideZone(lighted, &unused), ErrorMatches, "cannot override: zone does not run LightControllable")
+
+	c.Assert(s.zeus.OverrideZone(args, &unused), IsNil)
+	status := zeus.ZeusStatusReply{}
+	deadline := time.Now().Add(time.Second)
+	for time.Now().Before(deadline) {
+		c.Assert(s.zeus.Status(0, &status), IsNil)
+		if status.Zones["nest"].Override != nil {
+			break
+		}
+		time.Sleep(5 * time.Millisecond)
+	}
+	c.Assert(status.Zones["nest"].Override, Not(IsNil), Commentf("%+v", status))
+	c.Check(status.Zones["nest"].State.Humidity, Equals, zeus.Humidity(70))
+
+	c.Check(s.zeus.ClearOverride("nest", &unused), IsNil)
+	c.Check(s.zeus.ClearOverride("nest", &unused), ErrorMatches, "climate is not overridden")
+}
+
+func (s *ZeusSuite) TestHardwareLimits(c *C) {
+	cold := zeus.SeasonFile{
+		Zones: map[string]zeus.ZoneClimate{
+			"nest": zeus.ZoneClimate{
+				States: []zeus.State{
+					zeus.State{
+						Name:         "winter",
+						Temperature:  8,
+						Humidity:     zeus.UndefinedHumidity,
+						Wind:         zeus.UndefinedWind,
+						VisibleLight: zeus.UndefinedLight,
+						UVLight:      zeus.UndefinedLight,
+					},
+				},
+			},
+		},
+	}
+	c.Check(s.zeus.startClimate(cold), ErrorMatches,
+		"invalid season file: zone 'nest': state 'winter': temperature 8 is below the hardware minimum 15")
+	c.Check(s.zeus.isRunning(), Equals, false)
+
+	limits := zeus.DefaultHardwareLimits()
+	limits.Temperature.Min = 5
+	nest := s.zeus.definitions["nest"]
+	nest.Limits = &limits
+	s.zeus.definitions["nest"] = nest
+
+	status := zeus.ZeusStatusReply{}
+	c.Assert(s.zeus.Status(0, &status), IsNil)
+	c.Check(status.Limits["nest"], Equals, limits)
+	c.Check(status.Limits["tunnel"], Equals, zeus.DefaultHardwareLimits())
+
+	_, err := s.zeus.checkSeason(cold)
+	c.Check(err, IsNil)
+}
+
+func (s *ZeusSuite) TestUndrivenChannels(c *C) {
+	blueLight, err := zeus.RegisterChannel("blue-light", func(v float64) zeus.BoundedUnit { return zeus.Light(v) }, zeus.LinearChannel)
+	c.Assert(err, IsNil)
+	defer zeus.UnregisterChannel(blueLight)
+
+	state := zeus.State{
+		Name:         "day",
+		Temperature:  22,
+		Humidity:     zeus.UndefinedHumidity,
+		Wind:         zeus.UndefinedWind,
+		VisibleLight: zeus.UndefinedLight,
+		UVLight:      zeus.UndefinedLight,
+	}
+	season := zeus.SeasonFile{Zones: map[string]zeus.ZoneClimate{"nest": {States: []zeus.State{state}}}}
+	_, err = s.zeus.checkSeason(season)
+	c.Check(err, IsNil)
+
+	blueLight.Set(&state, 50)
+	season.Zones["nest"] = zeus.ZoneClimate{States: []zeus.State{state}}
+	_, err = s.zeus.checkSeason(season)
+	c.Check(err, ErrorMatches, "zone 'nest': no device drives channel 'blue-light'")
+	c.Check(s.zeus.startClimate(season), ErrorMatches, "invalid season file: zone 'nest': no device drives channel 'blue-light'")
+	c.Check(s.zeus.isRunning(), Equals, false)
+}
+
+func (s *ZeusSuite) TestFollowingZones(c *C) {
+	season := zeus.SeasonFile{
+		Zones: map[string]zeus.ZoneClimate{
+			"nest": zeus.ZoneClimate{
+				States: []zeus.State{
+					zeus.State{
+						Name:         "summer",
+						Temperature:  22,
+						Humidity:     zeus.UndefinedHumidity,
+						Wind:         zeus.UndefinedWind,
+						VisibleLight: zeus.UndefinedLight,
+						UVLight:      zeus.UndefinedLight,
+					},
+				},
+			},
+			"tunnel": zeus.ZoneClimate{
+				Follow:     "nest",
+				TimeOffset: 3 * time.Hour,
+				Offsets:    map[string]float64{"temperature": 2},
+			},
+		},
+	}
+	zones, err := s.zeus.checkSeason(season)
+	c.Assert(err, IsNil)
+	c.Check(zones["tunnel"].States[0].Temperature, Equals, zeus.Temperature(24))
+
+	season.Zones["tunnel"] = zeus.ZoneClimate{Follow: "nest", Offsets: map[string]float64{"temperature": -10}}
+	_, err = s.zeus.checkSeason(season)
+	c.Check(err, ErrorMatches, "zone 'tunnel': state 'summer': temperature 12 is below the hardware minimum 15")
+
+	season.Zones["tunnel"] = zeus.ZoneClimate{Follow: "foraging"}
+	_, err = s.zeus.checkSeason(season)
+	c.Check(err, ErrorMatches, "zone 'tunnel' follows undefined zone 'foraging'")
+
+	season.Zones["tunnel"] = zeus.ZoneClimate{Follow: "nest"}
+	season.Zones["nest"] = zeus.ZoneClimate{Follow: "tunnel"}
+	c.Check(s.zeus.startClimate(season), ErrorMatches, "invalid season file: zones follow each other in a cycle: nest -> tunnel -> nest")
+	c.Check(s.zeus.isRunning(), Equals, false)
+}
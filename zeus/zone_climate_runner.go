@@ -5,7 +5,9 @@ import (
 	"log"
 	"os"
 	"path/filepath"
+	"reflect"
 	"sync"
+	"time"
 
 	"github.com/adrg/xdg"
 	"github.com/formicidae-tracker/libarke/src-go/arke"
@@ -19,13 +21,20 @@ type ZoneClimateRunner interface {
 	ClimateLog(start, end int) ([]zeus.ClimateReport, error)
 	AlarmLog(start, end int) ([]zeus.AlarmEvent, error)
 	Last() zeus.ZeusZoneStatus
+	CheckClimate(climate zeus.ZoneClimate) error
+	UpdateClimate(climate zeus.ZoneClimate) error
+	Override(s zeus.State, duration time.Duration) error
+	ClearOverride() error
 }
 
 type ZoneClimateRunnerOptions struct {
 	Name        string
 	Definition  ZoneDefinition
 	FileSuffix  string
+	Reference   time.Time
+	ResumeLogs  bool
 	Dispatcher  ArkeDispatcher
+	Heartbeats  *heartbeatRecord
 	Climate     zeus.ZoneClimate
 	OlympusHost string
 	SlackClient *slack.Client
@@ -35,12 +44,15 @@ type ZoneClimateRunnerOptions struct {
 type zoneClimateRunner struct {
 	logger     *log.Logger
 	dispatcher ArkeDispatcher
+	definition ZoneDefinition
+	reference  time.Time
 
 	quit, done chan struct{}
 
 	messages <-chan *StampedMessage
 
 	interpoler      Interpoler
+	interpolerDone  chan struct{}
 	capabilities    []capability
 	presenceMonitor PresenceMonitorer
 	alarmMonitor    AlarmMonitor
@@ -57,6 +69,13 @@ type zoneClimateRunner struct {
 	climateLog, alarmLog string
 	climateLogData       []zeus.ClimateReport
 	alarmLogData         []zeus.AlarmEvent
+
+	mx        sync.Mutex
+	endReport *zeus.StateReport
+	stopped   bool
+	// overrideUntil is the end of the current manual override.
+	overrideUntil time.Time
+	heartbeats    *heartbeatRecord
 }
 
 func (r *zoneClimateRunner) spawnAlarmMonitor(wg *sync.WaitGroup) {
@@ -94,8 +113,10 @@ func (r *zoneClimateRunner) spawnReporters(wg *sync.WaitGroup) {
 func (r *zoneClimateRunner) spawnInterpoler(wg *sync.WaitGroup) {
 	wg.Add(1)
 	ready := make(chan struct{})
+	r.interpolerDone = make(chan struct{})
 	go func() {
 		r.interpoler.Interpolate(ready)
+		close(r.interpolerDone)
 		wg.Done()
 	}()
 	<-ready
@@ -113,6 +134,11 @@ func (r *zoneClimateRunner) spawnInterpoler(wg *sync.WaitGroup) {
 	wg.Add(1)
 	go func() {
 		for report := range r.interpoler.Reports() {
+			if report.Ended == true {
+				r.mx.Lock()
+				r.endReport = &report
+				r.mx.Unlock()
+			}
 			for _, reporters := range r.stateReporters {
 				reporters.StateChannel() <- report
 			}
@@ -163,13 +189,18 @@ func (r *zoneClimateRunner) stopTasks() {
 		capability.Close()
 	}
 
+	r.mx.Lock()
+	r.stopped = true
 	close(r.alarmMonitor.Inbound())
+	r.mx.Unlock()
 }
 
 func (r *zoneClimateRunner) handleMessage(m *StampedMessage, wg *sync.WaitGroup) {
 	switch m.M.MessageClassID() {
 	case arke.HeartBeatMessage:
-		r.presenceMonitor.Ping(m.M.(*arke.HeartBeatData).Class, m.ID)
+		class := m.M.(*arke.HeartBeatData).Class
+		r.heartbeats.Record(class, m.ID, m.T)
+		r.presenceMonitor.Ping(class, m.ID)
 	case arke.ErrorReportMessage:
 		e := m.M.(*arke.ErrorReportData)
 		r.alarmMonitor.Inbound() <- zeus.NewDeviceInternalError(r.dispatcher.Name(), e.Class, e.ID, e.ErrorCode)
@@ -212,6 +243,11 @@ func (r *zoneClimateRunner) Run() {
 			wgCallback.Wait()
 			r.stopTasks()
 			return
+		case <-r.interpolerDone:
+			r.logger.Printf("experiment ended, stopping climate control")
+			wgCallback.Wait()
+			r.stopTasks()
+			return
 		case m := <-r.messages:
 			r.handleMessage(m, &wgCallback)
 		}
@@ -230,8 +266,171 @@ func (r *zoneClimateRunner) Close() error {
 	return nil
 }
 
+// sameCapabilities returns an error if a and b do not control the
+// same devices.
+func sameCapabilities(a, b []capability) error {
+	if len(a) == len(b) {
+		same := true
+		for i := range a {
+			if reflect.TypeOf(a[i]) != reflect.TypeOf(b[i]) {
+				same = false
+			}
+		}
+		if same == true {
+			return nil
+		}
+	}
+	names := func(cs []capability) []string {
+		res := make([]string, 0, len(cs))
+		for _, c := range cs {
+			res = append(res, reflect.TypeOf(c).Elem().Name())
+		}
+		return res
+	}
+	return fmt.Errorf("new climate requires capabilities %v, but zone runs with %v", names(b), names(a))
+}
+
+func (r *zoneClimateRunner) newClimateInterpoler(climate zeus.ZoneClimate) (zeus.ClimateInterpoler, error) {
+	newInterpoler, err := zeus.NewZoneClimateInterpoler(climate, r.reference.UTC())
+	if err != nil {
+		return nil, err
+	}
+	capabilities := ComputeClimateRequirements(climate, r.definition, r.climateReporters)
+	if err := sameCapabilities(r.capabilities, capabilities); err != nil {
+		return nil, err
+	}
+	return newInterpoler, nil
+}
+
+// CheckClimate returns an error if the zone cannot be updated with
+// climate.
+func (r *zoneClimateRunner) CheckClimate(climate zeus.ZoneClimate) error {
+	_, err := r.newClimateInterpoler(climate)
+	return err
+}
+
+// UpdateClimate replaces the climate of a running zone. The day count
+// and logs of the experiment are kept, and the change is recorded in
+// the alarm log.
+func (r *zoneClimateRunner) UpdateClimate(climate zeus.ZoneClimate) error {
+	newInterpoler, err := r.newClimateInterpoler(climate)
+	if err != nil {
+		return err
+	}
+	schedule, err := zeus.NewZoneClimateInterpoler(climate, r.reference.UTC())
+	if err != nil {
+		return err
+	}
+
+	r.mx.Lock()
+	defer r.mx.Unlock()
+	if r.stopped == true || r.endReport != nil {
+		return fmt.Errorf("experiment already ended")
+	}
+	if err := r.interpoler.Update(newInterpoler); err != nil {
+		return err
+	}
+	for _, c := range r.capabilities {
+		if recordable, ok := c.(*ClimateRecordable); ok == true {
+			recordable.SetBounds(climate.MinimalTemperature,
+				climate.MaximalTemperature,
+				climate.MinimalHumidity,
+				climate.MaximalHumidity)
+			recordable.SetAlarmFilter(climate.AlarmDelay, climate.Hysteresis)
+			recordable.SetRateLimits(climate.RateWindow, climate.MaximalTemperatureRate, climate.MaximalHumidityRate)
+			recordable.SetSchedule(schedule)
+		}
+	}
+	r.alarmMonitor.Inbound() <- zeus.ClimateUpdated
+	r.logger.Printf("climate updated")
+	return nil
+}
+
+// controls returns an error if the zone devices cannot control all
+// defined fields of s.
+func (r *zoneClimateRunner) controls(s zeus.State) error {
+	needed := ComputeClimateRequirements(zeus.ZoneClimate{States: []zeus.State{s}}, r.definition, nil)
+	for _, n := range needed {
+		covered := false
+		for _, c := range r.capabilities {
+			if reflect.TypeOf(c) != reflect.TypeOf(n) {
+				continue
+			}
+			covered = true
+			for _, class := range n.Requirements() {
+				found := false
+				for _, available := range c.Requirements() {
+					found = found || available == class
+				}
+				covered = covered && found
+			}
+		}
+		if covered == false {
+			return fmt.Errorf("zone does not run %s", reflect.TypeOf(n).Elem().Name())
+		}
+	}
+	return nil
+}
+
+// Override forces the defined fields of s for duration, then resumes
+// the schedule.
+func (r *zoneClimateRunner) Override(s zeus.State, duration time.Duration) error {
+	now := time.Now()
+	o, err := zeus.NewOverride(s, now, duration)
+	if err != nil {
+		return err
+	}
+	if err := r.controls(s); err != nil {
+		return fmt.Errorf("cannot override: %s", err)
+	}
+
+	r.mx.Lock()
+	defer r.mx.Unlock()
+	if r.stopped == true || r.endReport != nil {
+		return fmt.Errorf("experiment already ended")
+	}
+	if err := r.interpoler.Override(o); err != nil {
+		return err
+	}
+	r.overrideUntil = o.Until
+	r.setRecordableOverride(now, o.Until)
+	r.alarmMonitor.Inbound() <- zeus.NewClimateOverriddenAlarm(duration)
+	r.logger.Printf("%s", o)
+	return nil
+}
+
+// ClearOverride ends the current manual override.
+func (r *zoneClimateRunner) ClearOverride() error {
+	r.mx.Lock()
+	defer r.mx.Unlock()
+	if time.Now().Before(r.overrideUntil) == false {
+		return fmt.Errorf("climate is not overridden")
+	}
+	if r.stopped == true || r.endReport != nil {
+		return fmt.Errorf("experiment already ended")
+	}
+	if err := r.interpoler.Override(nil); err != nil {
+		return err
+	}
+	r.overrideUntil = time.Time{}
+	r.setRecordableOverride(time.Now(), time.Time{})
+	r.alarmMonitor.Inbound() <- zeus.NewClimateOverriddenAlarm(0)
+	r.logger.Printf("override cleared")
+	return nil
+}
+
+// setRecordableOverride tells the recordable capabilities when the
+// schedule is overridden, or resumed if until is zero.
+func (r *zoneClimateRunner) setRecordableOverride(now, until time.Time) {
+	for _, c := range r.capabilities {
+		if recordable, ok := c.(*ClimateRecordable); ok == true {
+			recordable.SetOverride(now, until)
+		}
+	}
+}
+
 func (r *zoneClimateRunner) setUpInterpoler(o ZoneClimateRunnerOptions) error {
-	interpoler, err := NewInterpoler(o.Name, o.Climate.States, o.Climate.Transitions)
+	interpoler, err := NewInterpoler(o.Name, o.Climate, o.Reference)
 	if err != nil {
 		return err
 	}
@@ -264,14 +463,22 @@ func (r *zoneClimateRunner) fileName(name, suffix, ftype string) (string, error)
 }
 
 func (r *zoneClimateRunner) setUpFileReporters(o ZoneClimateRunnerOptions) error {
-	cr, _, err := NewFileClimateReporter(r.climateLog, o.Definition.TemperatureAux)
+	newClimateReporter := NewFileClimateReporter
+	newAlarmReporter := NewFileAlarmReporter
+	if o.ResumeLogs == true {
+		newClimateReporter = ResumeFileClimateReporter
+		newAlarmReporter = ResumeFileAlarmReporter
+	}
+
+	cr, fname, err := newClimateReporter(r.climateLog, o.Definition.TemperatureAux)
 	if err != nil {
 		return err
 	}
+	r.climateLog = fname
 	r.reporters = append(r.reporters, cr)
 	r.climateReporters = append(r.climateReporters, cr)
 
-	ar, err := NewFileAlarmReporter(r.alarmLog)
+	ar, err := newAlarmReporter(r.alarmLog)
 	if err != nil {
 		return err
 	}
@@ -291,6 +498,17 @@ func (r *zoneClimateRunner) setUpAlarmMonitor(o ZoneClimateRunnerOptions) error
 
 func (r *zoneClimateRunner) setUpCapabilities(o ZoneClimateRunnerOptions) error {
 	r.capabilities = ComputeClimateRequirements(o.Climate, o.Definition, r.climateReporters)
+	// the interpoler loop owns its schedule, the recordable needs its
+	// own.
+	schedule, err := zeus.NewZoneClimateInterpoler(o.Climate, o.Reference.UTC())
+	if err != nil {
+		return err
+	}
+	for _, c := range r.capabilities {
+		if recordable, ok := c.(*ClimateRecordable); ok == true {
+			recordable.SetSchedule(schedule)
+		}
+	}
 	return nil
 }
 
@@ -335,6 +553,7 @@ func (r *zoneClimateRunner) setUpSlackReporter(o ZoneClimateRunnerOptions) error
 	}
 	r.reporters = append(r.reporters, aReporter)
 	r.alarmReporters = append(r.alarmReporters, aReporter)
+	r.stateReporters = append(r.stateReporters, aReporter)
 	return nil
 }
 
@@ -404,17 +623,31 @@ func (r *zoneClimateRunner) AlarmLog(start, end int) ([]zeus.AlarmEvent, error)
 }
 
 func (r *zoneClimateRunner) Last() zeus.ZeusZoneStatus {
-	return r.last.Last()
+	res := r.last.Last()
+	r.mx.Lock()
+	defer r.mx.Unlock()
+	if r.endReport != nil {
+		res.State = zeus.SanitizeState(r.endReport.Current)
+		res.End = r.endReport.End
+		res.Ended = true
+	}
+	return res
 }
 
 func NewZoneClimateRunner(o ZoneClimateRunnerOptions) (r ZoneClimateRunner, err error) {
 	res := &zoneClimateRunner{
 		logger:          log.New(os.Stderr, "[zone/"+o.Name+"] ", 0),
 		dispatcher:      o.Dispatcher,
+		definition:      o.Definition,
+		reference:       o.Reference,
 		messages:        o.Dispatcher.Register(arke.NodeID(o.Definition.DevicesID)),
 		presenceMonitor: NewPresenceMonitorer(o.Dispatcher.Name(), o.Dispatcher.Interface()),
 		devices:         make(map[arke.NodeClass]*Device),
 		callbacks:       make(map[arke.MessageClass][]callback),
+		heartbeats:      o.Heartbeats,
+	}
+	if res.heartbeats == nil {
+		res.heartbeats = newHeartbeatRecord()
 	}
 
 	res.climateLog, err = res.fileName(o.Name, o.FileSuffix, "climate")
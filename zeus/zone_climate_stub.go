@@ -48,7 +48,7 @@ func NewZoneClimateStub(args ZoneClimateStubArgs) (ZoneClimateRunner, error) {
 		timeRatio: args.timeRatio,
 	}
 	var err error
-	res.interpoler, err = zeus.NewClimateInterpoler(args.climate.States, args.climate.Transitions, time.Now().UTC())
+	res.interpoler, err = zeus.NewZoneClimateInterpoler(args.climate, time.Now().UTC())
 	if err != nil {
 		return nil, err
 	}
@@ -165,6 +165,22 @@ func (s *zoneClimateStub) Last() zeus.ZeusZoneStatus {
 	return zeus.ZeusZoneStatus{}
 }
 
+func (s *zoneClimateStub) CheckClimate(climate zeus.ZoneClimate) error {
+	return fmt.Errorf("simulated zones cannot be updated")
+}
+
+func (s *zoneClimateStub) UpdateClimate(climate zeus.ZoneClimate) error {
+	return fmt.Errorf("simulated zones cannot be updated")
+}
+
+func (s *zoneClimateStub) Override(zeus.State, time.Duration) error {
+	return fmt.Errorf("simulated zones cannot be overridden")
+}
+
+func (s *zoneClimateStub) ClearOverride() error {
+	return fmt.Errorf("simulated zones cannot be overridden")
+}
+
 func (s *zoneClimateStub) step(now time.Time) {
 	s.simulateClimate(now)
 	s.simulateAlarms(now)
@@ -203,6 +219,7 @@ func (s *zoneClimateStub) simulateAlarms(now time.Time) {
 		ae := zeus.AlarmEvent{
 			Flags:          a.Alarm.Flags(),
 			Reason:         a.Alarm.Reason(),
+			Description:    describeAlarm(a.Alarm),
 			ZoneIdentifier: zeus.ZoneIdentifier(s.host, s.zone),
 			Time:           now,
 		}
@@ -259,5 +276,9 @@ func (s *zoneClimateStub) sendReport(now, next time.Time) {
 		*report.Next = zeus.SanitizeState(s.next.State(next))
 		report.NextEnd = s.next.End()
 	}
+	if end, ok := s.interpoler.ExperimentEnd(); ok == true {
+		report.End = &end
+		report.Ended = now.Before(end) == false
+	}
 	s.rpcReporter.StateChannel() <- report
 }
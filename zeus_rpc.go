@@ -1,7 +1,13 @@
 package zeus
 
-import "time"
+import (
+	"time"
 
+	"github.com/formicidae-tracker/libarke/src-go/arke"
+)
+
+// ZeusStartArgs holds a season file expanded by ReadSeasonFile, without
+// any include, template or state inheritance left.
 type ZeusStartArgs struct {
 	Season  SeasonFile
 	Version string
@@ -11,6 +17,16 @@ type ZeusZoneStatus struct {
 	State       State
 	Temperature float64
 	Humidity    float64
+	// VaporPressureDeficit in kPa and AbsoluteHumidity in g/m³ are
+	// derived from the measured Temperature and Humidity.
+	VaporPressureDeficit float64
+	AbsoluteHumidity     float64
+	// End is the scheduled end of the experiment, if any, and Ended
+	// is true once it is reached.
+	End   *time.Time
+	Ended bool
+	// Override is the manual override of the zone climate, if any.
+	Override *Override
 }
 
 type ZeusStatusReply struct {
@@ -18,6 +34,45 @@ type ZeusStatusReply struct {
 	Since   time.Time
 	Version string
 	Zones   map[string]ZeusZoneStatus
+	// Limits are the hardware limits of all zones of the node,
+	// running or not.
+	Limits map[string]HardwareLimits
+}
+
+// ZeusDeviceStatus is a device required by a zone.
+type ZeusDeviceStatus struct {
+	Class arke.NodeClass
+	ID    arke.NodeID
+	// Monitored is true if the interface of the device is currently
+	// listened to. Otherwise whether the device answers is unknown.
+	Monitored bool
+	// LastHeartbeat is the last time the device answered a heartbeat
+	// request, possibly during a previous season, or nil if it was
+	// never seen.
+	LastHeartbeat *time.Time
+}
+
+// ZeusZoneValidation describes how a zone would run a season.
+type ZeusZoneValidation struct {
+	// Capabilities are the names of the capabilities the zone would
+	// run.
+	Capabilities []string
+	Interface    string
+	Devices      []ZeusDeviceStatus
+	// Olympus is the host climate would be reported to, if any.
+	Olympus string
+}
+
+// ZeusValidationReply describes how a node would start a season,
+// without starting it.
+type ZeusValidationReply struct {
+	Zones map[string]ZeusZoneValidation
+	// SlackUser would be notified of the zones events, as
+	// SlackUserID.
+	SlackUser   string
+	SlackUserID string
+	// Errors are the reasons the season could not start.
+	Errors []string
 }
 
 type ZeusLogArgs struct {
@@ -25,6 +80,14 @@ type ZeusLogArgs struct {
 	Start, End int
 }
 
+// ZeusOverrideArgs forces the defined fields of State in a zone for
+// Duration.
+type ZeusOverrideArgs struct {
+	ZoneName string
+	State    State
+	Duration time.Duration
+}
+
 type ZeusClimateLogReply struct {
 	Data []ClimateReport
 }
@@ -1,10 +1,105 @@
 package zeus
 
+import (
+	"fmt"
+	"time"
+)
+
 type ZoneClimate struct {
-	MinimalTemperature Temperature `yaml:"minimal-temperature,omitempty"`
-	MaximalTemperature Temperature `yaml:"maximal-temperature,omitempty"`
-	MinimalHumidity    Humidity    `yaml:"minimal-humidity,omitempty"`
-	MaximalHumidity    Humidity    `yaml:"maximal-humidity,omitempty"`
-	States             []State
-	Transitions        []Transition
+	MinimalTemperature Temperature    `yaml:"minimal-temperature,omitempty"`
+	MaximalTemperature Temperature    `yaml:"maximal-temperature,omitempty"`
+	MinimalHumidity    Humidity       `yaml:"minimal-humidity,omitempty"`
+	MaximalHumidity    Humidity       `yaml:"maximal-humidity,omitempty"`
+	Timezone           string         `yaml:"timezone,omitempty"`
+	Latitude           *float64       `yaml:"latitude,omitempty"`
+	Longitude          *float64       `yaml:"longitude,omitempty"`
+	Weather            *Weather       `yaml:"weather,omitempty"`
+	End                *ExperimentEnd `yaml:"end,omitempty"`
+	// AlarmDelay is how long a value should stay out of bound before
+	// an alarm is raised.
+	AlarmDelay time.Duration   `yaml:"alarm-delay,omitempty"`
+	Hysteresis AlarmHysteresis `yaml:"hysteresis,omitempty"`
+	// MaximalTemperatureRate (in °C/min) and MaximalHumidityRate (in
+	// % R.H./min) are the fastest changes measured over RateWindow
+	// allowed outside of transitions. They are not checked if zero.
+	MaximalTemperatureRate float64       `yaml:"maximal-temperature-rate,omitempty"`
+	MaximalHumidityRate    float64       `yaml:"maximal-humidity-rate,omitempty"`
+	RateWindow             time.Duration `yaml:"rate-window,omitempty"`
+	// Follow is the name of the zone whose schedule is followed,
+	// delayed by TimeOffset and with the channel values shifted by
+	// Offsets.
+	Follow      string             `yaml:"follow,omitempty"`
+	TimeOffset  time.Duration      `yaml:"time-offset,omitempty"`
+	Offsets     map[string]float64 `yaml:"offsets,omitempty"`
+	States      []State
+	Transitions []Transition
+}
+
+// AlarmHysteresis are the margins inside the alarm bounds a value
+// should come back within before an out of bound alarm clears.
+type AlarmHysteresis struct {
+	Temperature float64 `yaml:"temperature,omitempty"`
+	Humidity    float64 `yaml:"humidity,omitempty"`
+}
+
+// DefaultRateWindow is the period rates of change are measured over
+// when a zone does not define its rate-window.
+const DefaultRateWindow = 5 * time.Minute
+
+// Location returns the location used to compute transition start
+// times. It is UTC unless an IANA Timezone is specified.
+func (c ZoneClimate) Location() (*time.Location, error) {
+	if len(c.Timezone) == 0 {
+		return time.UTC, nil
+	}
+	if c.Timezone == "Local" {
+		return nil, fmt.Errorf("invalid timezone 'Local': an IANA timezone name is required")
+	}
+	location, err := time.LoadLocation(c.Timezone)
+	if err != nil {
+		return nil, fmt.Errorf("invalid timezone '%s': %s", c.Timezone, err)
+	}
+	return location, nil
+}
+
+// checkSite checks that the zone site is defined if any transition
+// depends on the sun position.
+func (c ZoneClimate) checkSite() error {
+	if c.Latitude != nil && (*c.Latitude < -90.0 || *c.Latitude > 90.0) {
+		return fmt.Errorf("invalid latitude %f: should be in [-90,90]", *c.Latitude)
+	}
+	if c.Longitude != nil && (*c.Longitude < -180.0 || *c.Longitude > 180.0) {
+		return fmt.Errorf("invalid longitude %f: should be in [-180,180]", *c.Longitude)
+	}
+	if c.Latitude != nil && c.Longitude != nil {
+		return nil
+	}
+	for _, t := range c.Transitions {
+		if t.SolarEvent != NoSolarEvent {
+			return fmt.Errorf("%s requires the zone latitude and longitude", t)
+		}
+	}
+	return nil
+}
+
+// checkAlarms checks that the alarm delay, hysteresis and rates are
+// not negative.
+func (c ZoneClimate) checkAlarms() error {
+	if c.AlarmDelay < 0 {
+		return fmt.Errorf("invalid alarm-delay %s: should be positive", c.AlarmDelay)
+	}
+	if c.Hysteresis.Temperature < 0 || c.Hysteresis.Humidity < 0 {
+		return fmt.Errorf("invalid hysteresis {temperature: %g, humidity: %g}: should be positive",
+			c.Hysteresis.Temperature, c.Hysteresis.Humidity)
+	}
+	if c.MaximalTemperatureRate < 0 {
+		return fmt.Errorf("invalid maximal-temperature-rate %g: should be positive", c.MaximalTemperatureRate)
+	}
+	if c.MaximalHumidityRate < 0 {
+		return fmt.Errorf("invalid maximal-humidity-rate %g: should be positive", c.MaximalHumidityRate)
+	}
+	if c.RateWindow < 0 {
+		return fmt.Errorf("invalid rate-window %s: should be positive", c.RateWindow)
+	}
+	return nil
 }
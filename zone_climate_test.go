@@ -12,6 +12,7 @@ type ZoneClimateSuite struct{}
 var _ = Suite(&ZoneClimateSuite{})
 
 func (s *ZoneClimateSuite) TestParsing(c *C) {
+	latitude, longitude := 46.52, 6.63
 	testdata := []struct {
 		Text string
 		Zone ZoneClimate
@@ -22,6 +23,16 @@ minimal-temperature: 24.0
 maximal-temperature: 31.0
 minimal-humidity: 40.0
 maximal-humidity: 80.0
+alarm-delay: 5m
+hysteresis:
+  temperature: 0.5
+  humidity: 3
+maximal-temperature-rate: 0.5
+maximal-humidity-rate: 2
+rate-window: 10m
+timezone: Europe/Zurich
+latitude: 46.52
+longitude: 6.63
 states:
   - name: day
     temperature: 29.0
@@ -44,10 +55,18 @@ transitions:
     duration: 1h03m1s
 `,
 			Zone: ZoneClimate{
-				MinimalTemperature: 24,
-				MaximalTemperature: 31,
-				MinimalHumidity:    40,
-				MaximalHumidity:    80,
+				MinimalTemperature:     24,
+				MaximalTemperature:     31,
+				MinimalHumidity:        40,
+				MaximalHumidity:        80,
+				AlarmDelay:             5 * time.Minute,
+				Hysteresis:             AlarmHysteresis{Temperature: 0.5, Humidity: 3},
+				MaximalTemperatureRate: 0.5,
+				MaximalHumidityRate:    2,
+				RateWindow:             10 * time.Minute,
+				Timezone:               "Europe/Zurich",
+				Latitude:               &latitude,
+				Longitude:              &longitude,
 				States: []State{
 					State{
 						Name:         "day",
@@ -93,3 +112,19 @@ transitions:
 		c.Check(res, DeepEquals, d.Zone)
 	}
 }
+
+func (s *ZoneClimateSuite) TestAlarmSettings(c *C) {
+	climate := ZoneClimate{
+		States: []State{{Name: "day", Temperature: 26, Humidity: UndefinedHumidity, Wind: UndefinedWind, VisibleLight: UndefinedLight, UVLight: UndefinedLight}},
+	}
+	c.Check(climate.checkAlarms(), IsNil)
+	climate.AlarmDelay = -time.Minute
+	_, err := NewZoneClimateInterpoler(climate, time.Now())
+	c.Check(err, ErrorMatches, "invalid alarm-delay -1m0s: should be positive")
+	climate.AlarmDelay = 0
+	climate.Hysteresis.Humidity = -2
+	c.Check(climate.checkAlarms(), ErrorMatches, `invalid hysteresis \{temperature: 0, humidity: -2\}: should be positive`)
+	climate.Hysteresis.Humidity = 0
+	climate.MaximalHumidityRate = -1
+	c.Check(climate.checkAlarms(), ErrorMatches, "invalid maximal-humidity-rate -1: should be positive")
+}